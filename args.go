@@ -0,0 +1,300 @@
+package qb
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrNoChanges is the error returned by UpdateChanged when the given models
+// have the same values in all the updatable columns.
+var ErrNoChanges = errors.New("no changes to update")
+
+// Query is a SQL query with the arguments required to execute it, for example:
+//
+//	q, err := builder.InsertQuery(user)
+//	if err != nil {
+//		return err
+//	}
+//	_, err = db.Exec(q.SQL, q.Args...)
+type Query struct {
+	SQL  string
+	Args []any
+}
+
+// InsertQuery returns the query to insert the given model, the arguments are
+// the values of the model in the same order as the columns. The columns with a
+// ValueExpr without placeholder are not part of the arguments.
+//
+// The columns set with DatabaseDefaults are omitted from the query and the
+// arguments if their value is the zero value, so the query may differ between
+// models.
+func (q *QueryBuilder) InsertQuery(model any) (Query, error) {
+	if err := q.writable("InsertQuery"); err != nil {
+		return Query{}, err
+	}
+	values, err := q.columnValues(model)
+	if err != nil {
+		return Query{}, err
+	}
+	columns := q.Columns
+	if len(q.DatabaseDefaults) > 0 {
+		columns = make([]string, 0, len(q.Columns))
+		for _, name := range q.Columns {
+			if indexOf(q.DatabaseDefaults, name) < 0 || !isZero(values[name]) {
+				columns = append(columns, name)
+			}
+		}
+	}
+	args := make([]any, 0, len(columns))
+	for _, name := range columns {
+		if _, ok := q.valueExpr(name, ""); ok {
+			args = append(args, values[name])
+		}
+	}
+	if len(columns) == len(q.Columns) {
+		return Query{SQL: q.Insert(), Args: args}, nil
+	}
+	sql := q.finish(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", q.Table, join(columns), q.valuesOf(columns, 1)))
+	return Query{SQL: sql, Args: args}, nil
+}
+
+// NamedInsertPositional returns the query of Insert, with positional binding
+// parameters, and the values of the given model to use with it, so models can
+// be inserted with drivers that do not support named parameters. Unlike
+// InsertQuery, all the columns are always inserted, so the query is the same
+// for all the models. The columns with a ValueExpr without placeholder are not
+// part of the arguments.
+func (q *QueryBuilder) NamedInsertPositional(model any) (string, []any, error) {
+	if err := q.writable("NamedInsertPositional"); err != nil {
+		return "", nil, err
+	}
+	values, err := q.columnValues(model)
+	if err != nil {
+		return "", nil, err
+	}
+	args := make([]any, 0, len(q.Columns))
+	for _, name := range q.Columns {
+		if _, ok := q.valueExpr(name, ""); ok {
+			args = append(args, values[name])
+		}
+	}
+	return q.finish(q.insert()), args, nil
+}
+
+// isZero reports whether v is nil or the zero value of its type.
+func isZero(v any) bool {
+	return v == nil || reflect.ValueOf(v).IsZero()
+}
+
+// UpdateQuery returns the query to update the given model, the arguments are
+// the values of the updatable columns followed by the id, the version if
+// VersionColumn is set, and the tenant if TenantColumn is set.
+func (q *QueryBuilder) UpdateQuery(model any) (Query, error) {
+	if err := q.writable("UpdateQuery"); err != nil {
+		return Query{}, err
+	}
+	if err := q.keyed("UpdateQuery"); err != nil {
+		return Query{}, err
+	}
+	values, err := q.columnValues(model)
+	if err != nil {
+		return Query{}, err
+	}
+	idName := q.idColumn()
+	args := make([]any, 0, len(q.Columns)+1)
+	for _, name := range q.Columns {
+		if q.isUpdatable(name, idName) {
+			args = append(args, values[name])
+		}
+	}
+	args = q.appendKeyArgs(args, values)
+	return Query{SQL: q.Update(), Args: args}, nil
+}
+
+// UpdateChanged returns the query to update only the columns that have a
+// different value in the old and new models, and the arguments to use with it.
+// The arguments are the new values of the changed columns followed by the id,
+// the version, if VersionColumn is set, and the tenant, if TenantColumn is set,
+// of the new model. The primary key, created_at, and version columns are never
+// updated.
+//
+// If no columns have changed, UpdateChanged returns ErrNoChanges, and the
+// update can be skipped.
+func (q *QueryBuilder) UpdateChanged(oldModel, newModel any) (string, []any, error) {
+	if err := q.writable("UpdateChanged"); err != nil {
+		return "", nil, err
+	}
+	if err := q.keyed("UpdateChanged"); err != nil {
+		return "", nil, err
+	}
+	columns, values, err := q.changedColumns(oldModel, newModel)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(columns) == 0 {
+		return "", nil, ErrNoChanges
+	}
+	args := make([]any, 0, len(columns)+2)
+	for _, name := range columns {
+		args = append(args, values[name])
+	}
+	args = q.appendKeyArgs(args, values)
+	return q.finish(q.update(columns)), args, nil
+}
+
+// ModifiedColumns returns the updatable columns that have a different value in
+// the old and new models, the columns that UpdateChanged would update, for
+// example, to audit the changes before updating a record. The primary key,
+// created_at, and version columns are never included, and the columns in nil
+// embedded structs have a nil value. It returns an empty list if no columns
+// have changed.
+func (q *QueryBuilder) ModifiedColumns(oldModel, newModel any) ([]string, error) {
+	columns, _, err := q.changedColumns(oldModel, newModel)
+	if err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
+// appendKeyArgs appends the arguments of the WHERE clause of the update
+// queries: the id, the version, and the tenant.
+func (q *QueryBuilder) appendKeyArgs(args []any, values map[string]any) []any {
+	args = append(args, values[q.idColumn()])
+	if q.VersionColumn != "" {
+		args = append(args, values[q.VersionColumn])
+	}
+	if q.TenantColumn != "" {
+		args = append(args, values[q.TenantColumn])
+	}
+	return args
+}
+
+// changedColumns returns the updatable columns with different values in the
+// old and new models, and the values of the new model.
+func (q *QueryBuilder) changedColumns(oldModel, newModel any) ([]string, map[string]any, error) {
+	oldValues, err := q.columnValues(oldModel)
+	if err != nil {
+		return nil, nil, err
+	}
+	newValues, err := q.columnValues(newModel)
+	if err != nil {
+		return nil, nil, err
+	}
+	var columns []string
+	for _, name := range q.updatableColumns() {
+		if !reflect.DeepEqual(oldValues[name], newValues[name]) {
+			columns = append(columns, name)
+		}
+	}
+	return columns, newValues, nil
+}
+
+// PgxNamedArgs returns the values of the given model by column name, the
+// returned map can be converted to pgx.NamedArgs and used with the named
+// queries if NamedType is AT.
+func (q *QueryBuilder) PgxNamedArgs(model any) (map[string]any, error) {
+	return q.columnValues(model)
+}
+
+// DeleteQuery returns the query to mark the record with the given id as
+// deleted, the arguments are the current time and the id, or just the id if
+// SoftDeleteBool is set. DeleteQuery cannot be used if TenantColumn is set,
+// because the tenant is not known.
+func (q *QueryBuilder) DeleteQuery(id any) (Query, error) {
+	if err := q.writable("DeleteQuery"); err != nil {
+		return Query{}, err
+	}
+	if err := q.keyed("DeleteQuery"); err != nil {
+		return Query{}, err
+	}
+	if q.TenantColumn != "" {
+		return Query{}, errors.New("DeleteQuery cannot be used with a tenant column")
+	}
+	return Query{SQL: q.Delete(), Args: q.DeleteArgs(id, time.Now())}, nil
+}
+
+// DeleteArgs returns the arguments to use with Delete in the expected order,
+// the deletion time and the id, or just the id if SoftDeleteBool is set. If
+// TenantColumn is set, the tenant must be appended to the returned arguments.
+func (q *QueryBuilder) DeleteArgs(id any, deletedAt time.Time) []any {
+	if q.SoftDeleteBool || q.DeletedAtNow {
+		return []any{id}
+	}
+	return []any{deletedAt, id}
+}
+
+// columnValues returns the values of the columns in the given struct, the
+// struct must contain all the columns of the query builder. Columns in nil
+// embedded structs have a nil value.
+func (q *QueryBuilder) columnValues(model any) (map[string]any, error) {
+	v, err := structOf(model)
+	if err != nil {
+		return nil, err
+	}
+	tags := q.columnTags
+	if len(tags) == 0 {
+		tags = defaultOptions().columnTags
+	}
+	values := make(map[string]any)
+	if err := structValues(v, v.Type(), tags, q.inferColumns, values); err != nil {
+		return nil, err
+	}
+	for _, name := range q.Columns {
+		if _, ok := values[name]; !ok {
+			return nil, fmt.Errorf("%w %q in %T", ErrUnknownColumn, name, model)
+		}
+	}
+	return values, nil
+}
+
+// structValues resolves the column values in the struct v of type typ
+// recursively, in the same way as fieldColumns resolves the column names. If v
+// is not valid, the struct comes from a nil pointer, and all the values are
+// nil.
+func structValues(v reflect.Value, typ reflect.Type, tags []string, infer bool, values map[string]any) error {
+	for i, n := 0, typ.NumField(); i < n; i++ {
+		field := typ.Field(i)
+		var fv reflect.Value
+		if v.IsValid() {
+			fv = v.Field(i)
+		}
+
+		// Get the values in embedded structs
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			if err := structValues(fv, field.Type, tags, infer, values); err != nil {
+				return err
+			}
+		case reflect.Ptr:
+			if elem := field.Type.Elem(); elem.Kind() == reflect.Struct {
+				ev := fv
+				if ev.IsValid() {
+					if ev.IsNil() {
+						ev = reflect.Value{}
+					} else {
+						ev = ev.Elem()
+					}
+				}
+				if err := structValues(ev, elem, tags, infer, values); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Get the values
+		if s := columnName(tags, infer, field); s != "" {
+			name, _ := parseColumn(s)
+			switch {
+			case !fv.IsValid():
+				values[name] = nil
+			case fv.CanInterface():
+				values[name] = fv.Interface()
+			default:
+				return fmt.Errorf("column %q is an unexported field", name)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,345 @@
+package qb
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testArgsModel struct {
+	ID string `db:"id"`
+	*TestModelWithTime
+	TenantID string `db:"tenant_id"`
+	Name     string `db:"name"`
+}
+
+type testArgsUnexported struct {
+	ID   string `db:"id"`
+	name string `db:"name"`
+}
+
+func TestQueryBuilder_InsertQuery(t *testing.T) {
+	now := time.Now()
+	q := Must(testArgsModel{})
+	tests := []struct {
+		name    string
+		model   any
+		want    Query
+		wantErr bool
+	}{
+		{"ok", &testArgsModel{ID: "1", TestModelWithTime: &TestModelWithTime{CreatedAt: now}, TenantID: "t1", Name: "jane"}, Query{
+			SQL:  "INSERT INTO test_args_model (id, created_at, deleted_at, tenant_id, name) VALUES ($1, $2, $3, $4, $5)",
+			Args: []any{"1", now, time.Time{}, "t1", "jane"},
+		}, false},
+		{"ok nil embedded", testArgsModel{ID: "1", Name: "jane"}, Query{
+			SQL:  "INSERT INTO test_args_model (id, created_at, deleted_at, tenant_id, name) VALUES ($1, $2, $3, $4, $5)",
+			Args: []any{"1", nil, nil, "", "jane"},
+		}, false},
+		{"fail not struct", "foo", Query{}, true},
+		{"fail missing columns", testTable{}, Query{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := q.InsertQuery(tt.model)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.InsertQuery() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryBuilder.InsertQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_InsertQuery_valueExprs(t *testing.T) {
+	q := Must(testArgsModel{}, ValueExpr("created_at", "NOW()"), ValueExpr("name", "upper({})"))
+	want := Query{
+		SQL:  "INSERT INTO test_args_model (id, created_at, deleted_at, tenant_id, name) VALUES ($1, NOW(), $2, $3, upper($4))",
+		Args: []any{"1", nil, "t1", "jane"},
+	}
+	got, err := q.InsertQuery(&testArgsModel{ID: "1", TenantID: "t1", Name: "jane"})
+	if err != nil {
+		t.Fatalf("QueryBuilder.InsertQuery() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("QueryBuilder.InsertQuery() = %v, want %v", got, want)
+	}
+}
+
+func TestQueryBuilder_InsertQuery_databaseDefaults(t *testing.T) {
+	now := time.Now()
+	q := Must(testArgsModel{}, DatabaseDefaults("created_at", "deleted_at", "tenant_id"))
+	tests := []struct {
+		name  string
+		model any
+		want  Query
+	}{
+		{"ok zero values", &testArgsModel{ID: "1", TestModelWithTime: &TestModelWithTime{}, Name: "jane"}, Query{
+			SQL:  "INSERT INTO test_args_model (id, name) VALUES ($1, $2)",
+			Args: []any{"1", "jane"},
+		}},
+		{"ok nil embedded", &testArgsModel{ID: "1", TenantID: "t1", Name: "jane"}, Query{
+			SQL:  "INSERT INTO test_args_model (id, tenant_id, name) VALUES ($1, $2, $3)",
+			Args: []any{"1", "t1", "jane"},
+		}},
+		{"ok values", &testArgsModel{ID: "1", TestModelWithTime: &TestModelWithTime{CreatedAt: now, DeletedAt: now}, TenantID: "t1", Name: "jane"}, Query{
+			SQL:  "INSERT INTO test_args_model (id, created_at, deleted_at, tenant_id, name) VALUES ($1, $2, $3, $4, $5)",
+			Args: []any{"1", now, now, "t1", "jane"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := q.InsertQuery(tt.model)
+			if err != nil {
+				t.Fatalf("QueryBuilder.InsertQuery() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryBuilder.InsertQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_NamedInsertPositional(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		opts     []Option
+		model    any
+		want     string
+		wantArgs []any
+		wantErr  bool
+	}{
+		{"ok", nil, &testArgsModel{ID: "1", TestModelWithTime: &TestModelWithTime{CreatedAt: now}, TenantID: "t1", Name: "jane"},
+			"INSERT INTO test_args_model (id, created_at, deleted_at, tenant_id, name) VALUES ($1, $2, $3, $4, $5)",
+			[]any{"1", now, time.Time{}, "t1", "jane"}, false},
+		{"ok question", []Option{BindType(QUESTION)}, testArgsModel{ID: "1", Name: "jane"},
+			"INSERT INTO test_args_model (id, created_at, deleted_at, tenant_id, name) VALUES (?, ?, ?, ?, ?)",
+			[]any{"1", nil, nil, "", "jane"}, false},
+		{"ok database defaults", []Option{DatabaseDefaults("created_at", "deleted_at")}, testArgsModel{ID: "1", Name: "jane"},
+			"INSERT INTO test_args_model (id, created_at, deleted_at, tenant_id, name) VALUES ($1, $2, $3, $4, $5)",
+			[]any{"1", nil, nil, "", "jane"}, false},
+		{"ok value exprs", []Option{ValueExpr("created_at", "NOW()")}, testArgsModel{ID: "1", Name: "jane"},
+			"INSERT INTO test_args_model (id, created_at, deleted_at, tenant_id, name) VALUES ($1, NOW(), $2, $3, $4)",
+			[]any{"1", nil, "", "jane"}, false},
+		{"fail not struct", nil, "foo", "", nil, true},
+		{"fail missing columns", nil, testTable{}, "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := Must(testArgsModel{}, tt.opts...)
+			got, args, err := q.NamedInsertPositional(tt.model)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.NamedInsertPositional() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.NamedInsertPositional() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("QueryBuilder.NamedInsertPositional() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_UpdateQuery(t *testing.T) {
+	now := time.Now()
+	model := &testArgsModel{ID: "1", TestModelWithTime: &TestModelWithTime{CreatedAt: now}, TenantID: "t1", Name: "jane"}
+	tests := []struct {
+		name    string
+		q       *QueryBuilder
+		model   any
+		want    Query
+		wantErr bool
+	}{
+		{"ok", Must(testArgsModel{}), model, Query{
+			SQL:  "UPDATE test_args_model SET deleted_at = $1, tenant_id = $2, name = $3 WHERE id = $4",
+			Args: []any{time.Time{}, "t1", "jane", "1"},
+		}, false},
+		{"ok tenant", Must(testArgsModel{}, TenantColumn("tenant_id")), model, Query{
+			SQL:  "UPDATE test_args_model SET deleted_at = $1, tenant_id = $2, name = $3 WHERE id = $4 AND tenant_id = $5",
+			Args: []any{time.Time{}, "t1", "jane", "1", "t1"},
+		}, false},
+		{"ok struct literal", &QueryBuilder{Table: "users", Columns: []string{"id", "name"}}, model, Query{
+			SQL:  "UPDATE users SET name = $1 WHERE id = $2",
+			Args: []any{"jane", "1"},
+		}, false},
+		{"fail unexported", &QueryBuilder{Table: "users", Columns: []string{"id", "name"}}, testArgsUnexported{}, Query{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.q.UpdateQuery(tt.model)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.UpdateQuery() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryBuilder.UpdateQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_DeleteQuery(t *testing.T) {
+	q := Must(testArgsModel{})
+	got, err := q.DeleteQuery("1")
+	if err != nil {
+		t.Fatalf("QueryBuilder.DeleteQuery() error = %v", err)
+	}
+	if want := "UPDATE test_args_model SET deleted_at = $1 WHERE id = $2"; got.SQL != want {
+		t.Errorf("QueryBuilder.DeleteQuery() SQL = %v, want %v", got.SQL, want)
+	}
+	if len(got.Args) != 2 || got.Args[1] != "1" {
+		t.Errorf("QueryBuilder.DeleteQuery() Args = %v, want [<time> 1]", got.Args)
+	}
+	if _, ok := got.Args[0].(time.Time); !ok {
+		t.Errorf("QueryBuilder.DeleteQuery() Args[0] = %T, want time.Time", got.Args[0])
+	}
+
+	q = Must(testArgsModel{}, TenantColumn("tenant_id"))
+	if _, err := q.DeleteQuery("1"); err == nil {
+		t.Error("QueryBuilder.DeleteQuery() error = nil, want error")
+	}
+}
+
+func TestQueryBuilder_UpdateChanged(t *testing.T) {
+	now := time.Now()
+	older := now.Add(-time.Hour)
+	base := testArgsModel{ID: "1", TestModelWithTime: &TestModelWithTime{CreatedAt: older}, TenantID: "t1", Name: "jane"}
+	changed := testArgsModel{ID: "1", TestModelWithTime: &TestModelWithTime{CreatedAt: now, DeletedAt: now}, TenantID: "t1", Name: "john"}
+	nilEmbedded := testArgsModel{ID: "1", TenantID: "t1", Name: "jane"}
+	tests := []struct {
+		name     string
+		q        *QueryBuilder
+		old, new any
+		want     string
+		wantArgs []any
+		wantErr  error
+	}{
+		{"ok", Must(testArgsModel{}), base, &changed,
+			"UPDATE test_args_model SET deleted_at = $1, name = $2 WHERE id = $3", []any{now, "john", "1"}, nil},
+		{"ok tenant", Must(testArgsModel{}, TenantColumn("tenant_id")), &base, changed,
+			"UPDATE test_args_model SET deleted_at = $1, name = $2 WHERE id = $3 AND tenant_id = $4", []any{now, "john", "1", "t1"}, nil},
+		{"ok nil pointer", Must(testArgsModel{}), nilEmbedded, base,
+			"UPDATE test_args_model SET deleted_at = $1 WHERE id = $2", []any{time.Time{}, "1"}, nil},
+		{"no changes", Must(testArgsModel{}), base, base, "", nil, ErrNoChanges},
+		{"no changes only created_at", Must(testArgsModel{}), base, testArgsModel{ID: "1", TestModelWithTime: &TestModelWithTime{CreatedAt: now}, TenantID: "t1", Name: "jane"}, "", nil, ErrNoChanges},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotArgs, err := tt.q.UpdateChanged(tt.old, tt.new)
+			if err != tt.wantErr {
+				t.Errorf("QueryBuilder.UpdateChanged() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.UpdateChanged() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("QueryBuilder.UpdateChanged() args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+
+	if _, _, err := Must(testArgsModel{}).UpdateChanged("foo", base); err == nil {
+		t.Error("QueryBuilder.UpdateChanged() error = nil, want error")
+	}
+}
+
+func TestQueryBuilder_ModifiedColumns(t *testing.T) {
+	now := time.Now()
+	base := testArgsModel{ID: "1", TestModelWithTime: &TestModelWithTime{CreatedAt: now.Add(-time.Hour)}, TenantID: "t1", Name: "jane"}
+	changed := testArgsModel{ID: "2", TestModelWithTime: &TestModelWithTime{CreatedAt: now, DeletedAt: now}, TenantID: "t2", Name: "john"}
+	nilEmbedded := testArgsModel{ID: "1", TenantID: "t1", Name: "jane"}
+	tests := []struct {
+		name     string
+		q        *QueryBuilder
+		old, new any
+		want     []string
+		wantErr  bool
+	}{
+		{"ok", Must(testArgsModel{}), base, &changed, []string{"deleted_at", "tenant_id", "name"}, false},
+		{"ok nil pointer", Must(testArgsModel{}), nilEmbedded, base, []string{"deleted_at"}, false},
+		{"ok no changes", Must(testArgsModel{}), base, base, nil, false},
+		{"fail old", Must(testArgsModel{}), "foo", base, nil, true},
+		{"fail new", Must(testArgsModel{}), base, testTable{}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.q.ModifiedColumns(tt.old, tt.new)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.ModifiedColumns() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryBuilder.ModifiedColumns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_PgxNamedArgs(t *testing.T) {
+	q := Must(testArgsModel{}, NamedType(AT))
+	got, err := q.PgxNamedArgs(testArgsModel{ID: "1", TenantID: "t1", Name: "jane"})
+	if err != nil {
+		t.Fatalf("QueryBuilder.PgxNamedArgs() error = %v", err)
+	}
+	want := map[string]any{"id": "1", "created_at": nil, "deleted_at": nil, "tenant_id": "t1", "name": "jane"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("QueryBuilder.PgxNamedArgs() = %v, want %v", got, want)
+	}
+	if _, err := q.PgxNamedArgs(testTable{}); err == nil {
+		t.Error("QueryBuilder.PgxNamedArgs() error = nil, want error")
+	}
+}
+
+func TestQueryBuilder_DeleteArgs(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		q    *QueryBuilder
+		want []any
+	}{
+		{"ok", Must(testArgsModel{}), []any{now, "1"}},
+		{"ok soft delete bool", Must(testArgsModel{}, SoftDeleteBool("deleted")), []any{"1"}},
+		{"ok deleted at now", Must(testArgsModel{}, DeletedAtNow(true)), []any{"1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.q.DeleteArgs("1", now); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryBuilder.DeleteArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type testVersionModel struct {
+	ID      string `db:"id"`
+	Name    string `db:"name"`
+	Version int    `db:"version"`
+}
+
+func TestQueryBuilder_UpdateQuery_optimisticLock(t *testing.T) {
+	q := Must(testVersionModel{}, OptimisticLock("version"))
+	want := Query{
+		SQL:  "UPDATE test_version_model SET name = $1, version = version + 1 WHERE id = $2 AND version = $3",
+		Args: []any{"jane", "1", 2},
+	}
+	got, err := q.UpdateQuery(testVersionModel{ID: "1", Name: "jane", Version: 2})
+	if err != nil {
+		t.Fatalf("QueryBuilder.UpdateQuery() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("QueryBuilder.UpdateQuery() = %v, want %v", got, want)
+	}
+
+	sql, args, err := q.UpdateChanged(testVersionModel{ID: "1", Name: "john", Version: 2}, testVersionModel{ID: "1", Name: "jane", Version: 2})
+	if err != nil {
+		t.Fatalf("QueryBuilder.UpdateChanged() error = %v", err)
+	}
+	if sql != want.SQL || !reflect.DeepEqual(args, want.Args) {
+		t.Errorf("QueryBuilder.UpdateChanged() = %v, %v, want %v, %v", sql, args, want.SQL, want.Args)
+	}
+}
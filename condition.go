@@ -0,0 +1,141 @@
+package qb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Condition represents a boolean expression used in the WHERE clause of the
+// queries generated with SelectWhere. Conditions are created with the functions
+// Eq, Ne, Gt, Gte, Lt, Lte, IsNull, IsNotNull, And, and Or.
+type Condition interface {
+	build(q *QueryBuilder, args *[]any) string
+}
+
+type comparison struct {
+	column string
+	op     string
+	value  any
+}
+
+func (c comparison) build(q *QueryBuilder, args *[]any) string {
+	*args = append(*args, c.value)
+	return c.column + " " + c.op + " " + q.bindFor(c.column, len(*args))
+}
+
+type nullCheck struct {
+	column string
+	not    bool
+}
+
+func (c nullCheck) build(q *QueryBuilder, args *[]any) string {
+	return q.nullCheck(c.column, c.not)
+}
+
+type junction struct {
+	op    string
+	conds []Condition
+}
+
+func (c junction) build(q *QueryBuilder, args *[]any) string {
+	var parts []string
+	for _, cond := range c.conds {
+		if cond == nil {
+			continue
+		}
+		if s := cond.build(q, args); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	switch len(parts) {
+	case 0:
+		return ""
+	case 1:
+		return parts[0]
+	default:
+		return "(" + strings.Join(parts, " "+c.op+" ") + ")"
+	}
+}
+
+// Eq returns a condition that checks that a column is equal to a value.
+func Eq(column string, value any) Condition {
+	return comparison{column, "=", value}
+}
+
+// Ne returns a condition that checks that a column is not equal to a value.
+func Ne(column string, value any) Condition {
+	return comparison{column, "<>", value}
+}
+
+// Gt returns a condition that checks that a column is greater than a value.
+func Gt(column string, value any) Condition {
+	return comparison{column, ">", value}
+}
+
+// Gte returns a condition that checks that a column is greater than or equal
+// to a value.
+func Gte(column string, value any) Condition {
+	return comparison{column, ">=", value}
+}
+
+// Lt returns a condition that checks that a column is less than a value.
+func Lt(column string, value any) Condition {
+	return comparison{column, "<", value}
+}
+
+// Lte returns a condition that checks that a column is less than or equal to
+// a value.
+func Lte(column string, value any) Condition {
+	return comparison{column, "<=", value}
+}
+
+// IsNull returns a condition that checks that a column is NULL.
+func IsNull(column string) Condition {
+	return nullCheck{column: column}
+}
+
+// IsNotNull returns a condition that checks that a column is not NULL.
+func IsNotNull(column string) Condition {
+	return nullCheck{column: column, not: true}
+}
+
+// And returns a condition that is true if all the given conditions are true.
+func And(conds ...Condition) Condition {
+	return junction{"AND", conds}
+}
+
+// Or returns a condition that is true if any of the given conditions is true.
+func Or(conds ...Condition) Condition {
+	return junction{"OR", conds}
+}
+
+// SoftDeletePredicate returns the condition used to filter out deleted
+// records. It can be combined with other conditions, for example, to get the
+// live records and the ones deleted after a given time:
+//
+//	q.SelectWhere(qb.Or(q.SoftDeletePredicate(), qb.Gt("deleted_at", cutoff)))
+//
+// If SoftDeleteBool is set, the condition compares the column with false.
+func (q *QueryBuilder) SoftDeletePredicate() Condition {
+	if q.SoftDeleteBool {
+		return Eq(q.deletedColumn(), false)
+	}
+	return IsNull(q.deletedColumn())
+}
+
+// SelectWhere returns a query to get the records matching the given condition
+// and the arguments to use with it. Unlike other select queries, SelectWhere
+// does not filter deleted records automatically, use SoftDeletePredicate to do
+// it. If TenantColumn is set, the tenant predicate is added using the binding
+// parameter after the returned arguments, and the tenant must be appended to
+// them.
+func (q *QueryBuilder) SelectWhere(cond Condition) (string, []any) {
+	var args []any
+	var preds []string
+	if cond != nil {
+		if s := cond.build(q, &args); s != "" {
+			preds = append(preds, s)
+		}
+	}
+	return q.finish(fmt.Sprintf("SELECT %s FROM %s%s", q.columns(), q.Table, q.where(len(args)+1, false, preds...))), args
+}
@@ -0,0 +1,46 @@
+package qb
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestQueryBuilder_SelectWhere(t *testing.T) {
+	cutoff := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	q := &QueryBuilder{
+		Table:   "users",
+		Columns: []string{"id", "name", "deleted_at"},
+	}
+	tests := []struct {
+		name     string
+		bindType BindParam
+		cond     Condition
+		want     string
+		wantArgs []any
+	}{
+		{"nil", DOLLAR, nil, "SELECT id, name, deleted_at FROM users", nil},
+		{"eq", DOLLAR, Eq("name", "jane"), "SELECT id, name, deleted_at FROM users WHERE name = $1", []any{"jane"}},
+		{"soft delete", DOLLAR, q.SoftDeletePredicate(), "SELECT id, name, deleted_at FROM users WHERE deleted_at IS NULL", nil},
+		{"or", DOLLAR, Or(q.SoftDeletePredicate(), Gt("deleted_at", cutoff)),
+			"SELECT id, name, deleted_at FROM users WHERE (deleted_at IS NULL OR deleted_at > $1)", []any{cutoff}},
+		{"nested", DOLLAR, And(Ne("name", "jane"), Or(IsNull("deleted_at"), Gte("deleted_at", cutoff)), Lt("id", 10), Lte("id", 5)),
+			"SELECT id, name, deleted_at FROM users WHERE (name <> $1 AND (deleted_at IS NULL OR deleted_at >= $2) AND id < $3 AND id <= $4)", []any{"jane", cutoff, 10, 5}},
+		{"question", QUESTION, And(Eq("name", "jane"), IsNotNull("deleted_at")),
+			"SELECT id, name, deleted_at FROM users WHERE (name = ? AND deleted_at IS NOT NULL)", []any{"jane"}},
+		{"single", DOLLAR, And(nil, Or(Eq("id", 1))), "SELECT id, name, deleted_at FROM users WHERE id = $1", []any{1}},
+		{"empty", DOLLAR, And(Or()), "SELECT id, name, deleted_at FROM users", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q.BindType = tt.bindType
+			got, gotArgs := q.SelectWhere(tt.cond)
+			if got != tt.want {
+				t.Errorf("QueryBuilder.SelectWhere() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("QueryBuilder.SelectWhere() args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+		})
+	}
+}
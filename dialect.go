@@ -0,0 +1,198 @@
+package qb
+
+import "fmt"
+
+// SQLDialect represents the database flavor the queries are generated for.
+type SQLDialect int
+
+const (
+	// POSTGRES is the dialect used by PostgreSQL.
+	POSTGRES SQLDialect = iota + 1
+	// MYSQL is the dialect used by MySQL and MariaDB.
+	MYSQL
+	// SQLITE is the dialect used by SQLite.
+	SQLITE
+	// SQLSERVER is the dialect used by Microsoft SQL Server.
+	SQLSERVER
+)
+
+// String returns the name of the dialect.
+func (d SQLDialect) String() string {
+	switch d {
+	case POSTGRES:
+		return "postgres"
+	case MYSQL:
+		return "mysql"
+	case SQLITE:
+		return "sqlite"
+	case SQLSERVER:
+		return "sqlserver"
+	default:
+		return "generic"
+	}
+}
+
+// dialect returns the dialect of the query builder. If no dialect has been
+// configured, DOLLAR binding parameters imply PostgreSQL; QUESTION binding
+// parameters are shared by several databases and return the generic dialect.
+func (q *QueryBuilder) dialect() SQLDialect {
+	if q.Dialect != 0 {
+		return q.Dialect
+	}
+	if q.BindType == QUESTION {
+		return 0
+	}
+	return POSTGRES
+}
+
+// unsupported returns the error used when a query is not supported by the
+// dialect of the query builder.
+func (q *QueryBuilder) unsupported(method string) error {
+	return fmt.Errorf("%w: %s is not supported by the %s dialect", ErrUnsupported, method, q.dialect())
+}
+
+// SupportsReturning reports whether the dialect of the query builder supports
+// the RETURNING clause in insert, update, and delete queries. It is true for
+// PostgreSQL and SQLite (3.35 and later), and false for MySQL, SQL Server, and
+// the generic dialect, where the id of an inserted record must be obtained with
+// sql.Result.LastInsertId.
+func (q *QueryBuilder) SupportsReturning() bool {
+	switch q.dialect() {
+	case POSTGRES, SQLITE:
+		return true
+	default:
+		return false
+	}
+}
+
+// NowExpr returns the SQL expression used to get the current timestamp in the
+// dialect of the query builder. It returns NOW() for PostgreSQL and MySQL,
+// SYSUTCDATETIME() for SQL Server, and the portable CURRENT_TIMESTAMP for
+// SQLite and the generic dialect. It is the value used by the soft-delete
+// queries if DeletedAtNow is set.
+func (q *QueryBuilder) NowExpr() string {
+	return q.format(q.nowExpr())
+}
+
+func (q *QueryBuilder) nowExpr() string {
+	switch q.dialect() {
+	case POSTGRES, MYSQL:
+		return "NOW()"
+	case SQLSERVER:
+		return "SYSUTCDATETIME()"
+	default:
+		return "CURRENT_TIMESTAMP"
+	}
+}
+
+// Explain returns the given query prefixed with the statement that shows its
+// execution plan in the dialect of the query builder, EXPLAIN in PostgreSQL,
+// MySQL, and the generic dialect, and EXPLAIN QUERY PLAN in SQLite. SQL Server
+// is not supported.
+func (q *QueryBuilder) Explain(query string) (string, error) {
+	switch q.dialect() {
+	case SQLITE:
+		return q.finish("EXPLAIN QUERY PLAN " + query), nil
+	case SQLSERVER:
+		return "", q.unsupported("Explain")
+	default:
+		return q.finish("EXPLAIN " + query), nil
+	}
+}
+
+// ExplainAnalyze returns the given query prefixed with EXPLAIN ANALYZE, that
+// executes the query and shows its execution plan with the actual run times.
+// Note that the query is executed, so inserts, updates, and deletes will modify
+// the data. It is only supported by PostgreSQL and MySQL.
+func (q *QueryBuilder) ExplainAnalyze(query string) (string, error) {
+	switch q.dialect() {
+	case POSTGRES, MYSQL:
+		return q.finish("EXPLAIN ANALYZE " + query), nil
+	default:
+		return "", q.unsupported("ExplainAnalyze")
+	}
+}
+
+// Analyze returns the statement that updates the planner statistics of the
+// table, ANALYZE users in PostgreSQL and SQLite, and ANALYZE TABLE users in
+// MySQL. SQL Server and the generic dialect are not supported.
+func (q *QueryBuilder) Analyze() (string, error) {
+	switch q.dialect() {
+	case POSTGRES, SQLITE:
+		return q.finish("ANALYZE " + q.Table), nil
+	case MYSQL:
+		return q.finish("ANALYZE TABLE " + q.Table), nil
+	default:
+		return "", q.unsupported("Analyze")
+	}
+}
+
+// SetConstraintsDeferred returns the statement that defers the checks of all
+// the deferrable constraints until the end of the current transaction, SET
+// CONSTRAINTS ALL DEFERRED. It must be executed inside a transaction, and it
+// is only supported by PostgreSQL. It does not depend on the table of the
+// query builder.
+func (q *QueryBuilder) SetConstraintsDeferred() (string, error) {
+	if q.dialect() != POSTGRES {
+		return "", q.unsupported("SetConstraintsDeferred")
+	}
+	return q.finish("SET CONSTRAINTS ALL DEFERRED"), nil
+}
+
+// SetConstraintsImmediate returns the statement that checks all the
+// constraints immediately, including the pending deferred checks of the
+// current transaction, SET CONSTRAINTS ALL IMMEDIATE. It is only supported by
+// PostgreSQL.
+func (q *QueryBuilder) SetConstraintsImmediate() (string, error) {
+	if q.dialect() != POSTGRES {
+		return "", q.unsupported("SetConstraintsImmediate")
+	}
+	return q.finish("SET CONSTRAINTS ALL IMMEDIATE"), nil
+}
+
+// AdvisoryLock returns the query that acquires a session-level advisory lock
+// on a key, waiting until the lock is available, for example, to coordinate
+// the processes that work on the same record:
+//
+//	SELECT pg_advisory_lock(hashtext($1))
+//
+// The key is the only argument and it is hashed to the integer used by the
+// lock, so different keys might share a lock. The lock is held until it is
+// released with AdvisoryUnlock or the session ends. It is only supported by
+// PostgreSQL, and it does not depend on the table of the query builder.
+func (q *QueryBuilder) AdvisoryLock() (string, error) {
+	return q.advisory("AdvisoryLock", "pg_advisory_lock")
+}
+
+// AdvisoryUnlock returns the query that releases the session-level advisory
+// lock acquired with AdvisoryLock on a key, SELECT
+// pg_advisory_unlock(hashtext($1)). It is only supported by PostgreSQL.
+func (q *QueryBuilder) AdvisoryUnlock() (string, error) {
+	return q.advisory("AdvisoryUnlock", "pg_advisory_unlock")
+}
+
+// AdvisoryXactLock returns the query that acquires a transaction-level advisory
+// lock on a key, SELECT pg_advisory_xact_lock(hashtext($1)). The lock is
+// released automatically at the end of the current transaction and it cannot
+// be released with AdvisoryUnlock. It is only supported by PostgreSQL.
+func (q *QueryBuilder) AdvisoryXactLock() (string, error) {
+	return q.advisory("AdvisoryXactLock", "pg_advisory_xact_lock")
+}
+
+func (q *QueryBuilder) advisory(method, fn string) (string, error) {
+	if q.dialect() != POSTGRES {
+		return "", q.unsupported(method)
+	}
+	return q.finish("SELECT " + fn + "(hashtext(" + q.bind(1) + "))"), nil
+}
+
+// Vacuum returns the statement that reclaims the storage of the deleted and
+// updated rows of the table, VACUUM users. It is only supported by PostgreSQL;
+// VACUUM in SQLite cannot be scoped to a table. Note that VACUUM cannot run
+// inside a transaction block.
+func (q *QueryBuilder) Vacuum() (string, error) {
+	if q.dialect() != POSTGRES {
+		return "", q.unsupported("Vacuum")
+	}
+	return q.finish("VACUUM " + q.Table), nil
+}
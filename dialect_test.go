@@ -0,0 +1,257 @@
+package qb
+
+import "testing"
+
+func TestQueryBuilder_NowExpr(t *testing.T) {
+	type fields struct {
+		BindType BindParam
+		Dialect  SQLDialect
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{"default", fields{0, 0}, "NOW()"},
+		{"dollar", fields{DOLLAR, 0}, "NOW()"},
+		{"question", fields{QUESTION, 0}, "CURRENT_TIMESTAMP"},
+		{"postgres", fields{DOLLAR, POSTGRES}, "NOW()"},
+		{"mysql", fields{QUESTION, MYSQL}, "NOW()"},
+		{"sqlite", fields{QUESTION, SQLITE}, "CURRENT_TIMESTAMP"},
+		{"sqlserver", fields{QUESTION, SQLSERVER}, "SYSUTCDATETIME()"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:    "users",
+				Columns:  []string{"id", "name"},
+				BindType: tt.fields.BindType,
+				Dialect:  tt.fields.Dialect,
+			}
+			if got := q.NowExpr(); got != tt.want {
+				t.Errorf("QueryBuilder.NowExpr() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_Explain(t *testing.T) {
+	type fields struct {
+		BindType BindParam
+		Dialect  SQLDialect
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		want           string
+		wantErr        bool
+		wantAnalyze    string
+		wantAnalyzeErr bool
+	}{
+		{"postgres", fields{DOLLAR, 0}, "EXPLAIN SELECT 1", false, "EXPLAIN ANALYZE SELECT 1", false},
+		{"mysql", fields{QUESTION, MYSQL}, "EXPLAIN SELECT 1", false, "EXPLAIN ANALYZE SELECT 1", false},
+		{"sqlite", fields{QUESTION, SQLITE}, "EXPLAIN QUERY PLAN SELECT 1", false, "", true},
+		{"generic", fields{QUESTION, 0}, "EXPLAIN SELECT 1", false, "", true},
+		{"sqlserver", fields{QUESTION, SQLSERVER}, "", true, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:    "users",
+				Columns:  []string{"id", "name"},
+				BindType: tt.fields.BindType,
+				Dialect:  tt.fields.Dialect,
+			}
+			got, err := q.Explain("SELECT 1")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.Explain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.Explain() = %v, want %v", got, tt.want)
+			}
+			got, err = q.ExplainAnalyze("SELECT 1")
+			if (err != nil) != tt.wantAnalyzeErr {
+				t.Errorf("QueryBuilder.ExplainAnalyze() error = %v, wantErr %v", err, tt.wantAnalyzeErr)
+			}
+			if got != tt.wantAnalyze {
+				t.Errorf("QueryBuilder.ExplainAnalyze() = %v, want %v", got, tt.wantAnalyze)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_Analyze(t *testing.T) {
+	type fields struct {
+		BindType BindParam
+		Dialect  SQLDialect
+	}
+	tests := []struct {
+		name          string
+		fields        fields
+		want          string
+		wantErr       bool
+		wantVacuum    string
+		wantVacuumErr bool
+	}{
+		{"postgres", fields{DOLLAR, 0}, "ANALYZE users", false, "VACUUM users", false},
+		{"mysql", fields{QUESTION, MYSQL}, "ANALYZE TABLE users", false, "", true},
+		{"sqlite", fields{QUESTION, SQLITE}, "ANALYZE users", false, "", true},
+		{"generic", fields{QUESTION, 0}, "", true, "", true},
+		{"sqlserver", fields{QUESTION, SQLSERVER}, "", true, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:    "users",
+				Columns:  []string{"id", "name"},
+				BindType: tt.fields.BindType,
+				Dialect:  tt.fields.Dialect,
+			}
+			got, err := q.Analyze()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.Analyze() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.Analyze() = %v, want %v", got, tt.want)
+			}
+			got, err = q.Vacuum()
+			if (err != nil) != tt.wantVacuumErr {
+				t.Errorf("QueryBuilder.Vacuum() error = %v, wantErr %v", err, tt.wantVacuumErr)
+			}
+			if got != tt.wantVacuum {
+				t.Errorf("QueryBuilder.Vacuum() = %v, want %v", got, tt.wantVacuum)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_SetConstraints(t *testing.T) {
+	type fields struct {
+		BindType BindParam
+		Dialect  SQLDialect
+	}
+	tests := []struct {
+		name          string
+		fields        fields
+		wantDeferred  string
+		wantImmediate string
+		wantErr       bool
+	}{
+		{"postgres", fields{DOLLAR, 0}, "SET CONSTRAINTS ALL DEFERRED", "SET CONSTRAINTS ALL IMMEDIATE", false},
+		{"mysql", fields{QUESTION, MYSQL}, "", "", true},
+		{"sqlite", fields{QUESTION, SQLITE}, "", "", true},
+		{"generic", fields{QUESTION, 0}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:    "users",
+				Columns:  []string{"id", "name"},
+				BindType: tt.fields.BindType,
+				Dialect:  tt.fields.Dialect,
+			}
+			got, err := q.SetConstraintsDeferred()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.SetConstraintsDeferred() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.wantDeferred {
+				t.Errorf("QueryBuilder.SetConstraintsDeferred() = %v, want %v", got, tt.wantDeferred)
+			}
+			got, err = q.SetConstraintsImmediate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.SetConstraintsImmediate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.wantImmediate {
+				t.Errorf("QueryBuilder.SetConstraintsImmediate() = %v, want %v", got, tt.wantImmediate)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_AdvisoryLock(t *testing.T) {
+	type fields struct {
+		BindType BindParam
+		Dialect  SQLDialect
+	}
+	tests := []struct {
+		name         string
+		fields       fields
+		wantLock     string
+		wantUnlock   string
+		wantXactLock string
+		wantErr      bool
+	}{
+		{"postgres", fields{DOLLAR, 0}, "SELECT pg_advisory_lock(hashtext($1))", "SELECT pg_advisory_unlock(hashtext($1))", "SELECT pg_advisory_xact_lock(hashtext($1))", false},
+		{"mysql", fields{QUESTION, MYSQL}, "", "", "", true},
+		{"sqlite", fields{QUESTION, SQLITE}, "", "", "", true},
+		{"sqlserver", fields{DOLLAR, SQLSERVER}, "", "", "", true},
+		{"generic", fields{QUESTION, 0}, "", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:    "users",
+				Columns:  []string{"id", "name"},
+				BindType: tt.fields.BindType,
+				Dialect:  tt.fields.Dialect,
+			}
+			got, err := q.AdvisoryLock()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.AdvisoryLock() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.wantLock {
+				t.Errorf("QueryBuilder.AdvisoryLock() = %v, want %v", got, tt.wantLock)
+			}
+			got, err = q.AdvisoryUnlock()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.AdvisoryUnlock() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.wantUnlock {
+				t.Errorf("QueryBuilder.AdvisoryUnlock() = %v, want %v", got, tt.wantUnlock)
+			}
+			got, err = q.AdvisoryXactLock()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.AdvisoryXactLock() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.wantXactLock {
+				t.Errorf("QueryBuilder.AdvisoryXactLock() = %v, want %v", got, tt.wantXactLock)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_SupportsReturning(t *testing.T) {
+	type fields struct {
+		BindType BindParam
+		Dialect  SQLDialect
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		want       bool
+		wantInsert string
+	}{
+		{"default", fields{0, 0}, true, "INSERT INTO users (name) VALUES ($1) RETURNING id"},
+		{"postgres", fields{DOLLAR, POSTGRES}, true, "INSERT INTO users (name) VALUES ($1) RETURNING id"},
+		{"sqlite", fields{QUESTION, SQLITE}, true, "INSERT INTO users (name) VALUES (?) RETURNING id"},
+		{"mysql", fields{QUESTION, MYSQL}, false, "INSERT INTO users (name) VALUES (?)"},
+		{"sqlserver", fields{QUESTION, SQLSERVER}, false, "INSERT INTO users (name) VALUES (?)"},
+		{"question", fields{QUESTION, 0}, false, "INSERT INTO users (name) VALUES (?)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:    "users",
+				Columns:  []string{"id", "name"},
+				BindType: tt.fields.BindType,
+				Dialect:  tt.fields.Dialect,
+			}
+			if got := q.SupportsReturning(); got != tt.want {
+				t.Errorf("QueryBuilder.SupportsReturning() = %v, want %v", got, tt.want)
+			}
+			if got := q.InsertWithReturning(); got != tt.wantInsert {
+				t.Errorf("QueryBuilder.InsertWithReturning() = %v, want %v", got, tt.wantInsert)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package qb
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrNotStruct is the error returned when the value or type used to create
+	// a query builder is neither a struct nor a pointer to one.
+	ErrNotStruct = errors.New("neither struct nor does it point to one")
+	// ErrMultiplePrimaryKeys is the error returned when more than one column is
+	// tagged as primary key.
+	ErrMultiplePrimaryKeys = errors.New("table cannot have more than one primary key")
+	// ErrNoColumns is the error returned when a query builder has no columns.
+	ErrNoColumns = errors.New("columns are empty")
+	// ErrUnknownColumn is the error returned when a column is not one of the
+	// columns of the query builder or the model.
+	ErrUnknownColumn = errors.New("unknown column")
+	// ErrUnsupported is the error returned when a query is not supported by the
+	// dialect of the query builder.
+	ErrUnsupported = errors.New("unsupported query")
+	// ErrReadOnly is the error returned by the write queries of a read-only
+	// query builder, see ReadOnlyTable.
+	ErrReadOnly = errors.New("read-only table")
+)
+
+// ValidationError is the error returned by Validate, it contains all the
+// problems found in the query builder. It can be checked with errors.Is
+// against the errors it contains.
+type ValidationError struct {
+	Errors []error
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	s := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		s[i] = err.Error()
+	}
+	return "invalid query builder: " + strings.Join(s, "; ")
+}
+
+// Is reports whether any of the errors matches target.
+func (e *ValidationError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap returns the errors found in the query builder.
+func (e *ValidationError) Unwrap() []error {
+	return e.Errors
+}
@@ -0,0 +1,89 @@
+package qb
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testMultiplePrimaryKeys struct {
+	ID    string `db:"id,pkey"`
+	Email string `db:"email,pkey"`
+}
+
+type testNoColumns struct {
+	Name string
+}
+
+func TestErrors(t *testing.T) {
+	q := &QueryBuilder{
+		Table:   "users",
+		Columns: []string{"id", "name"},
+		Dialect: MYSQL,
+	}
+	tests := []struct {
+		name string
+		fn   func() error
+		want error
+	}{
+		{"not struct", func() error {
+			_, err := New("foo")
+			return err
+		}, ErrNotStruct},
+		{"not struct type", func() error {
+			_, err := NewFromType(reflect.TypeOf(1))
+			return err
+		}, ErrNotStruct},
+		{"multiple primary keys", func() error {
+			_, err := New(testMultiplePrimaryKeys{})
+			return err
+		}, ErrMultiplePrimaryKeys},
+		{"no columns", func() error {
+			_, err := New(testNoColumns{})
+			return err
+		}, ErrNoColumns},
+		{"exclude unknown column", func() error {
+			_, err := New(testTable{}, ExcludeColumns("phone"))
+			return err
+		}, ErrUnknownColumn},
+		{"order by unknown column", func() error {
+			_, err := q.OrderBy("phone")
+			return err
+		}, ErrUnknownColumn},
+		{"unsupported", func() error {
+			_, err := q.Upsert()
+			return err
+		}, ErrUnsupported},
+		{"read-only", func() error {
+			_, err := Must(testTable{}, ReadOnlyTable(true)).DeleteQuery("1")
+			return err
+		}, ErrReadOnly},
+		{"validate no columns", func() error {
+			return (&QueryBuilder{Table: "users"}).Validate()
+		}, ErrNoColumns},
+		{"validate primary key", func() error {
+			return (&QueryBuilder{Table: "users", Columns: []string{"name"}, SelectDeleted: true, PrimaryKey: "id"}).Validate()
+		}, ErrUnknownColumn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fn(); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	err := (&QueryBuilder{Columns: []string{"name"}, SelectDeleted: true, PrimaryKey: "id"}).Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("QueryBuilder.Validate() error = %T, want *ValidationError", err)
+	}
+	if len(verr.Errors) != 2 {
+		t.Errorf("ValidationError.Errors = %v, want 2 errors", verr.Errors)
+	}
+	if errors.Is(err, ErrNoColumns) {
+		t.Errorf("errors.Is(%v, ErrNoColumns) = true, want false", err)
+	}
+}
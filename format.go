@@ -0,0 +1,135 @@
+package qb
+
+import "strings"
+
+// keywords are the SQL keywords and functions used in the generated queries.
+var keywords = map[string]bool{
+	"ALL": true, "ANALYZE": true, "AND": true, "AS": true, "ASC": true,
+	"BY": true, "CONFLICT": true, "CONSTRAINTS": true, "COUNT": true,
+	"CREATE": true, "CURRENT_TIMESTAMP": true, "DEFAULT": true, "DEFERRED": true,
+	"DELETE": true, "DESC": true, "DISTINCT": true, "DO": true, "EXCEPT": true,
+	"EXCLUDED": true, "EXISTS": true, "EXPLAIN": true, "FALSE": true,
+	"FETCH": true, "FIRST": true, "FOR": true, "FROM": true, "IMMEDIATE": true,
+	"IN": true, "INSERT": true, "INTERSECT": true, "INTO": true, "IS": true,
+	"ISNULL": true, "KEY": true, "LIMIT": true, "LOCK": true, "LOCKED": true,
+	"LOWER": true, "MATCHED": true, "MERGE": true, "MODE": true, "NOT": true,
+	"NOTNULL": true, "NOW": true, "NULL": true, "OF": true, "OFFSET": true,
+	"ON": true, "OR": true, "ORDER": true, "PLAN": true, "PRIMARY": true,
+	"QUERY": true, "RAND": true, "RANDOM": true, "RECURSIVE": true,
+	"RETURNING": true, "ROWS": true, "SELECT": true, "SET": true, "SHARE": true,
+	"SKIP": true, "SYSTEM": true, "SYSTEM_TIME": true, "SYSUTCDATETIME": true,
+	"TABLE": true, "TABLESAMPLE": true, "TEMPORARY": true, "TEXT": true,
+	"THEN": true, "TIES": true, "TOP": true, "TRUE": true, "UNION": true,
+	"UNLOGGED": true, "UPDATE": true, "USING": true, "VACUUM": true,
+	"VALUES": true, "WHEN": true, "WHERE": true, "WITH": true,
+}
+
+// finish applies the output options of the query builder to a generated
+// statement, and records it if the RecordQueries option is set.
+func (q *QueryBuilder) finish(s string) string {
+	s = q.format(s)
+	if q.queryLog != nil {
+		q.queryLog.add(s)
+	}
+	return s
+}
+
+// format applies the output options of the query builder to a generated
+// query or fragment, like the predicates of ByIDClause, without recording it.
+func (q *QueryBuilder) format(s string) string {
+	if len(q.ReservedWords) > 0 {
+		s = q.quoteReserved(s)
+	}
+	if q.Lowercase {
+		s = lowercaseKeywords(s)
+	}
+	return s
+}
+
+// lowercaseKeywords returns the query with the SQL keywords in lowercase. The
+// quoted strings and identifiers are not modified.
+func lowercaseKeywords(s string) string {
+	return mapWords(s, func(w string, _ byte) string {
+		if keywords[w] {
+			return strings.ToLower(w)
+		}
+		return w
+	})
+}
+
+// mapWords returns the query with the words, the keywords, identifiers, and
+// numbers, replaced by the result of fn. The function gets the word and the
+// character before it, or 0 at the start of the query. The quoted strings and
+// identifiers are not modified.
+func mapWords(s string, fn func(w string, prev byte) string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			j := i + 1
+			for j < len(s) && s[j] != c {
+				j++
+			}
+			if j < len(s) {
+				j++
+			}
+			b.WriteString(s[i:j])
+			i = j
+		case isWordChar(c):
+			j := i + 1
+			for j < len(s) && isWordChar(s[j]) {
+				j++
+			}
+			var prev byte
+			if i > 0 {
+				prev = s[i-1]
+			}
+			b.WriteString(fn(s[i:j], prev))
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// QuoteIdent returns the given identifier quoted with the identifier quotes of
+// the dialect of the query builder, backticks in MySQL and double quotes in the
+// rest. Qualified names like data.email are quoted per segment, "data"."email",
+// and the quotes in the name are escaped.
+func (q *QueryBuilder) QuoteIdent(name string) string {
+	quote := `"`
+	if q.dialect() == MYSQL {
+		quote = "`"
+	}
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = quote + strings.ReplaceAll(p, quote, quote+quote) + quote
+	}
+	return strings.Join(parts, ".")
+}
+
+// QuoteStringList returns the given values as a list of SQL string literals,
+// like ('active', 'pending'), escaping the single quotes in the values. An
+// empty list returns (NULL), so "status IN (NULL)" matches no records.
+//
+// QuoteStringList must only be used with trusted constant values, like the
+// values of an enum. It does not protect against SQL injection in all the
+// databases and character sets, user input must always use binding parameters.
+func QuoteStringList(vals []string) string {
+	if len(vals) == 0 {
+		return "(NULL)"
+	}
+	s := make([]string, len(vals))
+	for i, v := range vals {
+		s[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	}
+	return "(" + join(s) + ")"
+}
@@ -0,0 +1,107 @@
+package qb
+
+import "testing"
+
+func TestLowercaseKeywords(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"ok", "SELECT id, name FROM users WHERE id = $1 AND deleted_at IS NULL", "select id, name from users where id = $1 and deleted_at is null"},
+		{"ok functions", "UPDATE users SET updated_at = NOW(), n = COUNT(*)", "update users set updated_at = now(), n = count(*)"},
+		{"ok quoted", `SELECT 'SELECT', "FROM", ` + "`WHERE`" + ` FROM users`, `select 'SELECT', "FROM", ` + "`WHERE`" + ` from users`},
+		{"ok escaped quote", "SELECT 'it''s AND' AS s", "select 'it''s AND' as s"},
+		{"ok unterminated", "SELECT 'FROM", "select 'FROM"},
+		{"ok identifiers", "SELECT Selected, FROM_ID, orDER FROM Users", "select Selected, FROM_ID, orDER from Users"},
+		{"ok empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lowercaseKeywords(tt.s); got != tt.want {
+				t.Errorf("lowercaseKeywords() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_Lowercase(t *testing.T) {
+	q := &QueryBuilder{
+		Table:        "users",
+		Columns:      []string{"id", "name", "created_at", "deleted_at"},
+		TenantColumn: "org_id",
+		Lowercase:    true,
+	}
+	upsert, _ := q.Upsert()
+	deleteWithReturning, _ := q.DeleteWithReturning()
+	selectWhere, _ := q.SelectWhere(And(Eq("name", "jane"), IsNull("deleted_at")))
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"Select", q.Select(), "select id, name, created_at, deleted_at from users where id = $1 and org_id = $2 and deleted_at is null"},
+		{"SelectAll", q.SelectAll(), "select id, name, created_at, deleted_at from users where org_id = $1 and deleted_at is null"},
+		{"Insert", q.Insert(), "insert into users (id, name, created_at, deleted_at) values ($1, $2, $3, $4)"},
+		{"InsertWithReturning", q.InsertWithReturning(), "insert into users (name, created_at, deleted_at) values ($1, $2, $3) returning id"},
+		{"Update", q.Update(), "update users set name = $1, deleted_at = $2 where id = $3 and org_id = $4"},
+		{"Upsert", upsert, "insert into users (id, name, created_at, deleted_at) values ($1, $2, $3, $4) on conflict (id) do update set name = excluded.name, deleted_at = excluded.deleted_at where users.org_id = excluded.org_id"},
+		{"DeleteWithReturning", deleteWithReturning, "update users set deleted_at = $1 where id = $2 and org_id = $3 returning id, name, created_at, deleted_at"},
+		{"SelectWhere", selectWhere, "select id, name, created_at, deleted_at from users where (name = $1 and deleted_at is null) and org_id = $2"},
+		{"CreateTable", q.CreateTable(), "create table users (id text primary key, name text, created_at text, deleted_at text)"},
+		{"NowExpr", q.NowExpr(), "now()"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("QueryBuilder.%s() = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoteStringList(t *testing.T) {
+	tests := []struct {
+		name string
+		vals []string
+		want string
+	}{
+		{"ok", []string{"active", "pending"}, "('active', 'pending')"},
+		{"ok one", []string{"active"}, "('active')"},
+		{"ok quotes", []string{"it's", "''"}, "('it''s', '''''')"},
+		{"ok empty value", []string{""}, "('')"},
+		{"ok empty", nil, "(NULL)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := QuoteStringList(tt.vals); got != tt.want {
+				t.Errorf("QuoteStringList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_QuoteIdent(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect SQLDialect
+		ident   string
+		want    string
+	}{
+		{"ok", POSTGRES, "users", `"users"`},
+		{"ok qualified", POSTGRES, "data.email", `"data"."email"`},
+		{"ok escape", SQLITE, `my"table`, `"my""table"`},
+		{"ok mysql", MYSQL, "order", "`order`"},
+		{"ok mysql qualified", MYSQL, "data.email", "`data`.`email`"},
+		{"ok mysql escape", MYSQL, "my`table", "`my``table`"},
+		{"ok sqlserver", SQLSERVER, "data.email", `"data"."email"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{Dialect: tt.dialect}
+			if got := q.QuoteIdent(tt.ident); got != tt.want {
+				t.Errorf("QueryBuilder.QuoteIdent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package qb
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -10,8 +13,13 @@ const (
 	idColumn        = "id"
 	createdAtColumn = "created_at"
 	deletedAtColumn = "deleted_at"
+	defaultSQLType  = "TEXT"
 )
 
+// ValuePlaceholder is the marker replaced by the binding parameter, or the
+// named parameter, of a column in the expressions set with ValueExpr.
+const ValuePlaceholder = "{}"
+
 // BindParam represents the binding parameter in SQL queries.
 type BindParam int
 
@@ -25,28 +33,90 @@ const (
 	QUESTION
 )
 
+// NamedParam represents the named parameter style used in the named queries.
+type NamedParam int
+
+const (
+	// COLON is the named parameter style used by sqlx, the parameters use the
+	// character : and the column name. They look like :id, :name, ...
+	COLON NamedParam = iota + 1
+	// AT is the named parameter style used by pgx.NamedArgs, the parameters use
+	// the character @ and the column name. They look like @id, @name, ...
+	AT
+)
+
 // QueryBuilder provides a simple list of SQL queries that can be used by the
 // models. It requires tables with the columns id, created_at, and deleted_at.
 type QueryBuilder struct {
-	Table         string
-	Columns       []string
-	SelectDeleted bool
-	PrimaryKey    string
-	BindType      BindParam
+	Table             string
+	Columns           []string
+	SelectDeleted     bool
+	PrimaryKey        string
+	BindType          BindParam
+	NumberedBinds     bool
+	Dialect           SQLDialect
+	ColumnTypes       map[string]string
+	ValueExprs        map[string]string
+	Casts             map[string]string
+	TenantColumn      string
+	OrderByPrimaryKey bool
+	ReadOnly          bool
+	VersionColumn     string
+	DatabaseDefaults  []string
+	ReturningStar     bool
+	ReturningOrder    []string
+	SoftDeleteColumn  string
+	SoftDeleteBool    bool
+	DeletedAtNow      bool
+	Lowercase         bool
+	ShortIsNull       bool
+	Pretty            bool
+	ReservedWords     []string
+	NamedType         NamedParam
+	columnTags        []string
+	inferColumns      bool
+	queryLog          *queryLog
 }
 
 type options struct {
-	tableName string
-	tableTag  string
-	columnTag string
-	bindType  BindParam
+	tableName   string
+	tablePrefix string
+	tableTag    string
+	columnTags  []string
+	bindType    BindParam
+	numbered    bool
+	dialect     SQLDialect
+	columnTypes map[string]string
+	valueExprs  map[string]string
+	casts       map[string]string
+	tenant      string
+	orderByPK   bool
+	readOnly    bool
+	version     string
+	dbDefaults  []string
+	returnStar  bool
+	returnOrder []string
+	softDelete  string
+	softBool    bool
+	deletedNow  bool
+	lowercase   bool
+	shortIsNull bool
+	pretty      bool
+	quoteRsv    bool
+	reserved    []string
+	firstPK     bool
+	sortColumns bool
+	inferCols   bool
+	record      bool
+	namedType   NamedParam
+	exclude     []string
 }
 
 func defaultOptions() *options {
 	return &options{
-		tableTag:  "dbtable",
-		columnTag: "db",
-		bindType:  DOLLAR,
+		tableTag:   "dbtable",
+		columnTags: []string{"db"},
+		bindType:   DOLLAR,
 	}
 }
 
@@ -62,6 +132,17 @@ func TableName(name string) Option {
 	}
 }
 
+// TablePrefix sets a prefix added to the table name, for example, to use the
+// tables of an environment in a shared database, like staging_users. It is
+// added to the name set with TableName, the table tag, or the name derived from
+// the type, and if the name is qualified with a schema, like auth.users, it is
+// added to the table and not to the schema, auth.staging_users.
+func TablePrefix(prefix string) Option {
+	return func(o *options) {
+		o.tablePrefix = prefix
+	}
+}
+
 // TableTag sets the tag key used to get the table name. It defaults to
 // "dbtable".
 func TableTag(key string) Option {
@@ -75,9 +156,22 @@ func TableTag(key string) Option {
 // ColumnTag sets the tag key used to get a column name. It defaults to
 // "db".
 func ColumnTag(key string) Option {
+	return ColumnTags(key)
+}
+
+// ColumnTags sets the tag keys used to get a column name in priority order,
+// the first key with a non-empty value in a field is used. It defaults to
+// "db".
+func ColumnTags(keys ...string) Option {
 	return func(o *options) {
-		if key != "" {
-			o.columnTag = key
+		var tags []string
+		for _, key := range keys {
+			if key != "" {
+				tags = append(tags, key)
+			}
+		}
+		if len(tags) > 0 {
+			o.columnTags = tags
 		}
 	}
 }
@@ -91,6 +185,302 @@ func BindType(t BindParam) Option {
 	}
 }
 
+// NamedType defines the named parameter style used in the named queries. It
+// defaults to COLON.
+func NamedType(t NamedParam) Option {
+	return func(o *options) {
+		o.namedType = t
+	}
+}
+
+// NumberedPlaceholders defines if the binding parameters must include the
+// positional number even if the binding parameter type does not use it by
+// default. With QUESTION, the parameters look like ?1, ?2, ... DOLLAR parameters
+// are always numbered.
+func NumberedPlaceholders(v bool) Option {
+	return func(o *options) {
+		o.numbered = v
+	}
+}
+
+// Dialect defines the SQL dialect used for the dialect-specific parts of the
+// queries. If not set, the dialect is derived from the binding parameter type.
+func Dialect(d SQLDialect) Option {
+	return func(o *options) {
+		if d != 0 {
+			o.dialect = d
+		}
+	}
+}
+
+// ColumnType sets the SQL type of a column used by CreateTable. Columns without
+// a type use TEXT.
+func ColumnType(col, sqlType string) Option {
+	return func(o *options) {
+		if col != "" && sqlType != "" {
+			if o.columnTypes == nil {
+				o.columnTypes = make(map[string]string)
+			}
+			o.columnTypes[col] = sqlType
+		}
+	}
+}
+
+// ValueExpr sets the SQL expression used as the value of a column in the
+// insert queries, for example "crypt({}, gen_salt('bf'))". The ValuePlaceholder
+// in the expression is replaced by the binding parameter of the column, and it
+// must appear at most once. Expressions without the placeholder, like "NOW()",
+// do not use a binding parameter and the column is not part of the arguments.
+func ValueExpr(col, expr string) Option {
+	return func(o *options) {
+		if col != "" && expr != "" {
+			if o.valueExprs == nil {
+				o.valueExprs = make(map[string]string)
+			}
+			o.valueExprs[col] = expr
+		}
+	}
+}
+
+// Cast sets the SQL type used to cast the binding parameters of a column, so
+// they are rendered as $n::sqlType in the inserts, updates, and predicates that
+// use the column. It is required for columns like enums or arrays where the
+// driver cannot infer the type. Casts are only used with the DOLLAR binding
+// parameter type.
+func Cast(col, sqlType string) Option {
+	return func(o *options) {
+		if col != "" && sqlType != "" {
+			if o.casts == nil {
+				o.casts = make(map[string]string)
+			}
+			o.casts[col] = sqlType
+		}
+	}
+}
+
+// TenantColumn sets the column used to scope the queries by tenant. If set,
+// the select, update, and delete queries include the predicate
+// "tenant_column = $n" after the rest of the predicates, the tenant binding
+// parameter always goes after the other parameters in the WHERE clause.
+//
+// The WHERE clauses use a canonical order: first the predicates of the query,
+// like the id, then the tenant predicate, and finally the predicate that
+// filters the deleted records, that does not use binding parameters. The
+// parameters are numbered in that order without gaps, and parameters that go
+// after the WHERE clause, like LIMIT, use the next positions.
+func TenantColumn(name string) Option {
+	return func(o *options) {
+		o.tenant = name
+	}
+}
+
+// DefaultOrderByPrimaryKey defines if SelectAll must sort the records by
+// primary key, so results are returned in a deterministic order. It has no
+// effect on tables without a primary key. It defaults to false.
+func DefaultOrderByPrimaryKey(v bool) Option {
+	return func(o *options) {
+		o.orderByPK = v
+	}
+}
+
+// ReadOnlyTable defines if the table is read-only, like a database view used
+// by a read model. The select queries work as usual, but the methods that
+// return insert, update, or delete queries return an error, or panic if they
+// do not return errors. It defaults to false.
+func ReadOnlyTable(v bool) Option {
+	return func(o *options) {
+		o.readOnly = v
+	}
+}
+
+// OptimisticLock sets the version column used for optimistic locking. If set,
+// the update queries increment the version, "version = version + 1", and only
+// update the record if the version matches the one given, that goes after the
+// id in the arguments. An update that affects no rows means that the record
+// was modified concurrently or does not exist.
+func OptimisticLock(col string) Option {
+	return func(o *options) {
+		o.version = col
+	}
+}
+
+// DatabaseDefaults sets the columns with a default value in the database, like
+// created_at. InsertQuery omits these columns from the query and the arguments
+// if their value in the model is the zero value, so the database default is
+// used instead of values like the zero time. The queries returned by Insert are
+// not affected.
+func DatabaseDefaults(cols ...string) Option {
+	return func(o *options) {
+		o.dbDefaults = append(o.dbDefaults, cols...)
+	}
+}
+
+// ReturningStar defines if the queries that return all the columns of a
+// record, like InsertReturningAll or DeleteReturningAll, must use RETURNING *
+// instead of the list of columns. It defaults to false, the explicit list keeps
+// the order of the columns stable when scanning the results.
+func ReturningStar(v bool) Option {
+	return func(o *options) {
+		o.returnStar = v
+	}
+}
+
+// ReturningOrder sets the columns, and their order, returned by the queries
+// that return all the columns of a record, like InsertReturningAll, instead of
+// all the columns in the query builder order. The columns must be unique and
+// part of the query builder columns. ReturningStar takes precedence over it.
+func ReturningOrder(cols ...string) Option {
+	return func(o *options) {
+		o.returnOrder = cols
+	}
+}
+
+// DeletedAtColumn sets the timestamp column used to mark the deleted records,
+// like removed_at, instead of deleted_at. All the queries that filter, mark, or
+// restore deleted records use it.
+func DeletedAtColumn(col string) Option {
+	return func(o *options) {
+		if col != "" {
+			o.softDelete = col
+			o.softBool = false
+		}
+	}
+}
+
+// SoftDeleteBool configures the query builder to use a boolean column, like
+// is_deleted, to mark the deleted records instead of the deleted_at timestamp.
+// The deleted records are filtered with "is_deleted = FALSE", Delete sets the
+// column to TRUE, and Restore sets it back to FALSE. The deleted_at column is
+// not used in this mode.
+func SoftDeleteBool(col string) Option {
+	return func(o *options) {
+		if col != "" {
+			o.softDelete = col
+			o.softBool = true
+		}
+	}
+}
+
+// DeletedAtNow configures the query builder to mark the deleted records with
+// the current timestamp of the database, see NowExpr, instead of a deleted_at
+// value given as an argument:
+//
+//	UPDATE users SET deleted_at = NOW() WHERE id = $1
+//
+// The id is then the first binding parameter of Delete and CascadeDelete, as
+// returned by DeleteArgs and PrimaryKeyBindPositions. It has no effect if
+// SoftDeleteBool is set.
+func DeletedAtNow(v bool) Option {
+	return func(o *options) {
+		o.deletedNow = v
+	}
+}
+
+// Lowercase defines if the generated queries must use lowercase SQL keywords,
+// like "select id from users where id = $1". It defaults to false, using
+// uppercase keywords.
+func Lowercase(v bool) Option {
+	return func(o *options) {
+		o.lowercase = v
+	}
+}
+
+// ShortIsNull defines if the NULL checks must use the short PostgreSQL and
+// SQLite forms "deleted_at ISNULL" and "deleted_at NOTNULL" instead of
+// "deleted_at IS NULL" and "deleted_at IS NOT NULL". It can be used to match
+// exactly the predicate of partial indexes written in the short form. Other
+// dialects always use the standard form.
+func ShortIsNull(v bool) Option {
+	return func(o *options) {
+		o.shortIsNull = v
+	}
+}
+
+// Pretty defines if the statements that are usually checked into version
+// control, like migrations, must be formatted for readability: the column
+// definitions of CreateTable and the VALUES tuples of multi-row statements like
+// UpsertMany are written in their own indented lines. It only changes the
+// whitespace of the queries. It defaults to false, using compact queries.
+func Pretty(v bool) Option {
+	return func(o *options) {
+		o.pretty = v
+	}
+}
+
+// QuoteReserved configures the query builder to quote the table and column
+// names that are one of the given reserved words, ignoring the case, like
+// "user" or `order` in MySQL. The rest of the names are not quoted. If no words
+// are given, it uses MySQLReservedWords in MySQL and PostgresReservedWords in
+// the rest of the dialects.
+func QuoteReserved(words []string) Option {
+	return func(o *options) {
+		o.quoteRsv = true
+		o.reserved = words
+	}
+}
+
+// FirstFieldIsPrimaryKey defines if the first column must be used as the
+// primary key when no column is tagged as primary key. It defaults to false,
+// using the id column as the primary key.
+func FirstFieldIsPrimaryKey(v bool) Option {
+	return func(o *options) {
+		o.firstPK = v
+	}
+}
+
+// SortColumns defines if the columns must be sorted by name, with the primary
+// key first, instead of using the order of the struct fields. The positional
+// arguments of the queries follow the order of the columns, sorting them keeps
+// that order stable if the struct fields are reordered. It defaults to false.
+func SortColumns(v bool) Option {
+	return func(o *options) {
+		o.sortColumns = v
+	}
+}
+
+// ExcludeColumns removes the given columns from the query builder, so they are
+// not used in any query. New fails if any of the columns is not present in the
+// struct.
+func ExcludeColumns(cols ...string) Option {
+	return func(o *options) {
+		o.exclude = append(o.exclude, cols...)
+	}
+}
+
+// GormTags configures the query builder to read the columns from GORM tags,
+// like `gorm:"column:email;primaryKey"`, instead of the db tag. The column
+// option sets the column name, that defaults to the field name in snake case,
+// and the primaryKey option marks the primary key. Fields without a gorm tag
+// are not columns. It is equivalent to ColumnTag("gorm").
+func GormTags(v bool) Option {
+	return func(o *options) {
+		if v {
+			o.columnTags = []string{gormTag}
+		}
+	}
+}
+
+// InferColumns configures the query builder to use the exported fields without
+// a column tag as columns, with the field name in snake case, like first_name
+// for FirstName. Fields tagged with "-" and embedded structs are not columns.
+// It defaults to false, so only the tagged fields are columns.
+func InferColumns(v bool) Option {
+	return func(o *options) {
+		o.inferCols = v
+	}
+}
+
+// RecordQueries configures the query builder to record all the generated
+// queries, that can be retrieved with GeneratedQueries. It can be used in tests
+// to check the statements executed by a code path. Only complete statements are
+// recorded, not fragments like the ones returned by ByIDClause, ScanColumns, or
+// NowExpr. It defaults to false.
+func RecordQueries(v bool) Option {
+	return func(o *options) {
+		o.record = v
+	}
+}
+
 // WithColumnTag sets the tag key used to get a column name. It defaults to
 // "db".
 //
@@ -103,24 +493,115 @@ func WithColumnTag(key string) Option {
 // struct. By default it uses the tag "dbtable" for the table name and "db" for
 // the column names.
 func New(i any, opts ...Option) (*QueryBuilder, error) {
+	v, err := structOf(i)
+	if err != nil {
+		return nil, err
+	}
+	return newFromType(v.Type(), opts)
+}
+
+// NewFromType returns a new query builder configured with the fields tags in
+// the given struct type, or pointer to a struct type. It works like New, but it
+// does not require an instance of the struct.
+func NewFromType(t reflect.Type, opts ...Option) (*QueryBuilder, error) {
+	typ, err := structTypeOf(t)
+	if err != nil {
+		return nil, err
+	}
+	return newFromType(typ, opts)
+}
+
+func newFromType(typ reflect.Type, opts []Option) (*QueryBuilder, error) {
 	o := defaultOptions()
 	for _, fn := range opts {
 		fn(o)
 	}
-	t, err := getTable(i, o)
+	t, err := getTable(typ, o)
 	if err != nil {
 		return nil, err
 	}
+	if err := t.removeColumns(o.exclude); err != nil {
+		return nil, err
+	}
+	if len(t.Columns) == 0 {
+		return nil, fmt.Errorf("table %s: %w", t.Name, ErrNoColumns)
+	}
 	qb := NewQueryBuilder(t.Name, t.Columns)
-	if t.PrimaryKey != "" {
+	switch {
+	case t.PrimaryKey != "":
 		qb.PrimaryKey = t.PrimaryKey
+	case o.firstPK && len(t.Columns) > 0:
+		qb.PrimaryKey = t.Columns[0]
+	}
+	if o.sortColumns {
+		sortColumns(qb.Columns, qb.PrimaryKey)
 	}
 	if o.bindType != 0 {
 		qb.BindType = o.bindType
 	}
+	qb.NumberedBinds = o.numbered
+	qb.Dialect = o.dialect
+	qb.ColumnTypes = o.columnTypes
+	qb.ValueExprs = o.valueExprs
+	qb.Casts = o.casts
+	qb.TenantColumn = o.tenant
+	qb.OrderByPrimaryKey = o.orderByPK
+	qb.ReadOnly = o.readOnly
+	qb.VersionColumn = o.version
+	qb.DatabaseDefaults = o.dbDefaults
+	qb.ReturningStar = o.returnStar
+	qb.ReturningOrder = o.returnOrder
+	if o.softDelete != "" {
+		qb.SoftDeleteColumn = o.softDelete
+		qb.SoftDeleteBool = o.softBool
+		qb.SelectDeleted = !qb.HasColumn(o.softDelete)
+	}
+	qb.DeletedAtNow = o.deletedNow
+	qb.Lowercase = o.lowercase
+	qb.ShortIsNull = o.shortIsNull
+	qb.Pretty = o.pretty
+	if o.quoteRsv {
+		qb.ReservedWords = o.reserved
+		if len(qb.ReservedWords) == 0 {
+			qb.ReservedWords = defaultReservedWords(qb.dialect())
+		}
+	}
+	qb.NamedType = o.namedType
+	qb.columnTags = o.columnTags
+	qb.inferColumns = o.inferCols
+	if o.record {
+		qb.queryLog = new(queryLog)
+	}
+	if err := qb.validateReturningOrder(); err != nil {
+		return nil, err
+	}
 	return qb, nil
 }
 
+// NewPostgres returns a new query builder for PostgreSQL. It is equivalent to
+// New with the DOLLAR binding parameter type and the POSTGRES dialect.
+func NewPostgres(i any, opts ...Option) (*QueryBuilder, error) {
+	return New(i, withDialect(POSTGRES, DOLLAR, opts)...)
+}
+
+// NewMySQL returns a new query builder for MySQL. It is equivalent to New with
+// the QUESTION binding parameter type and the MYSQL dialect.
+func NewMySQL(i any, opts ...Option) (*QueryBuilder, error) {
+	return New(i, withDialect(MYSQL, QUESTION, opts)...)
+}
+
+// NewSQLite returns a new query builder for SQLite. It is equivalent to New
+// with the QUESTION binding parameter type and the SQLITE dialect.
+func NewSQLite(i any, opts ...Option) (*QueryBuilder, error) {
+	return New(i, withDialect(SQLITE, QUESTION, opts)...)
+}
+
+// withDialect prepends the dialect options to the given ones, so they can still
+// be overridden.
+func withDialect(d SQLDialect, t BindParam, opts []Option) []Option {
+	return append([]Option{Dialect(d), BindType(t)}, opts...)
+}
+
 // Must returns a new query builder configured with the fields tags in the given
 // struct. By default it uses the tag "dbtable" for the table name and "db" for
 // the column names.
@@ -135,164 +616,2059 @@ func Must(i any, opts ...Option) *QueryBuilder {
 }
 
 // NewQueryBuilder returns a new query builder configured with the given table
-// and columns.
+// and columns. If the columns do not include deleted_at, the query builder is
+// configured to not filter deleted records, and if they do not include id, it
+// is configured without a primary key, see HasPrimaryKey.
 func NewQueryBuilder(table string, columns []string) *QueryBuilder {
-	return &QueryBuilder{
+	q := &QueryBuilder{
 		Table:         table,
 		Columns:       columns,
 		SelectDeleted: false,
-		PrimaryKey:    idColumn,
 		BindType:      DOLLAR,
 	}
+	if q.HasColumn(idColumn) {
+		q.PrimaryKey = idColumn
+	}
+	q.SelectDeleted = !q.HasColumn(deletedAtColumn)
+	return q
 }
 
-// Queries returns the queries for select by id, insert,
-// update, and delete.
-func (q *QueryBuilder) Queries() (string, string, string, string) {
-	return q.Select(), q.Insert(), q.Update(), q.Delete()
+// ProjectOnto returns a new query builder with the configuration of the base
+// query builder, like the table, primary key, and binding parameters, but only
+// with the given columns. It can be used to derive read models with a subset of
+// the columns of a canonical model. The columns must include the primary key,
+// the tenant column, and the column used to filter the deleted records, if
+// they are columns of the base query builder, so the queries of the projection
+// keep working like the base ones. It returns an error if a column is not one
+// of the base columns, a required column is missing, or no columns are given.
+func ProjectOnto(base *QueryBuilder, cols ...string) (*QueryBuilder, error) {
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("table %s: %w", base.Table, ErrNoColumns)
+	}
+	for _, name := range cols {
+		if !base.HasColumn(name) {
+			return nil, fmt.Errorf("%w %q in table %s", ErrUnknownColumn, name, base.Table)
+		}
+	}
+	required := []string{base.idColumn(), base.TenantColumn}
+	if !base.SelectDeleted {
+		required = append(required, base.deletedColumn())
+	}
+	for _, name := range required {
+		if name != "" && base.HasColumn(name) && indexOf(cols, name) < 0 {
+			return nil, fmt.Errorf("column %q of table %s is required by the projection", name, base.Table)
+		}
+	}
+	q := base.clone()
+	q.Columns = append([]string(nil), cols...)
+	return q, nil
 }
 
-// Select returns the query to get a record by id.
-func (q *QueryBuilder) Select() string {
-	s := fmt.Sprintf("SELECT %s FROM %s WHERE %s = %s", q.columns(), q.Table, q.idColumn(), q.bind(1))
-	if !q.SelectDeleted {
-		s += " AND deleted_at IS NULL"
+// WithTable returns a copy of the query builder that uses the given table name,
+// for example, to generate the same queries for the partitions or shards of a
+// table, like events_2024_01. It returns an error if the name is empty.
+func (q *QueryBuilder) WithTable(name string) (*QueryBuilder, error) {
+	if name == "" {
+		return nil, errors.New("table name is empty")
 	}
-	return s
+	c := q.clone()
+	c.Table = name
+	return c, nil
 }
 
-// SelectBy returns a query to get a record by the given column name.
-func (q *QueryBuilder) SelectBy(name string, extraNames ...string) string {
-	s := fmt.Sprintf("SELECT %s FROM %s WHERE %s = %s", q.columns(), q.Table, name, q.bind(1))
-	// Append extra names.
-	for i, n := range extraNames {
-		s += fmt.Sprintf(" AND %s = %s", n, q.bind(i+2))
-	}
-	if !q.SelectDeleted {
-		s += " AND deleted_at IS NULL"
-	}
-	return s
+// clone returns a copy of the query builder that does not share the columns
+// and the other slices and maps with the original one. The recorded queries
+// are shared.
+func (q *QueryBuilder) clone() *QueryBuilder {
+	c := *q
+	c.Columns = cloneStrings(q.Columns)
+	c.DatabaseDefaults = cloneStrings(q.DatabaseDefaults)
+	c.ReturningOrder = cloneStrings(q.ReturningOrder)
+	c.ReservedWords = cloneStrings(q.ReservedWords)
+	c.columnTags = cloneStrings(q.columnTags)
+	c.ColumnTypes = cloneMap(q.ColumnTypes)
+	c.ValueExprs = cloneMap(q.ValueExprs)
+	c.Casts = cloneMap(q.Casts)
+	return &c
 }
 
-// SelectAll returns a query to get all entries in a table.
-func (q *QueryBuilder) SelectAll() string {
-	if !q.SelectDeleted {
-		return fmt.Sprintf("SELECT %s FROM %s WHERE deleted_at IS NULL", q.columns(), q.Table)
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
 	}
-	return fmt.Sprintf("SELECT %s FROM %s", q.columns(), q.Table)
+	return append([]string{}, s...)
 }
 
-// Insert returns the query to insert a record.
-func (q *QueryBuilder) Insert() string {
-	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", q.Table, q.columns(), q.values())
+func cloneMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
 }
 
-// InsertWithReturning returns the query to insert that returns the id.
-func (q *QueryBuilder) InsertWithReturning() string {
-	var pos = 1
-	var idName = q.idColumn()
-	var columns, values []string
-	for _, name := range q.Columns {
-		if name != idName {
-			columns = append(columns, name)
-			values = append(values, q.bind(pos))
-			pos++
-		}
-	}
-	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING %s", q.Table, join(columns), join(values), idName)
+// Queries returns the queries for select by id, insert,
+// update, and delete.
+func (q *QueryBuilder) Queries() (string, string, string, string) {
+	return q.Select(), q.Insert(), q.Update(), q.Delete()
 }
 
-// Insert returns the query to insert a record using named values.
-func (q *QueryBuilder) NamedInsert() string {
-	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", q.Table, q.columns(), q.namedValues())
+// SelectWith returns the same query as Select using the given binding
+// parameter type instead of BindType. SelectWith, InsertWith, UpdateWith, and
+// DeleteWith can be used to generate a query for a different driver without
+// creating a new query builder.
+func (q *QueryBuilder) SelectWith(bt BindParam) string {
+	return q.withBindType(bt).Select()
 }
 
-// NamedInsertWithReturning returns the query to insert a record using named
-// values, the query will return the id.
-func (q *QueryBuilder) NamedInsertWithReturning() string {
-	var idName = q.idColumn()
-	var columns, values []string
-	for _, name := range q.Columns {
-		if name != idName {
-			columns = append(columns, name)
-			values = append(values, ":"+name)
-		}
-	}
-	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING %s", q.Table, join(columns), join(values), idName)
+// InsertWith returns the same query as Insert using the given binding
+// parameter type instead of BindType.
+func (q *QueryBuilder) InsertWith(bt BindParam) string {
+	return q.withBindType(bt).Insert()
 }
 
-// Update returns the query to update a record. Update won't update neither the
-// id nor the created_at column.
-func (q *QueryBuilder) Update() string {
-	var v []string
-	var idName = q.idColumn()
-	pos := 1
-	for _, name := range q.Columns {
-		if name != idName && name != createdAtColumn {
-			v = append(v, name+" = "+q.bind(pos))
-			pos++
-		}
-	}
-	return fmt.Sprintf("UPDATE %s SET %s WHERE %s = %s", q.Table, join(v), q.idColumn(), q.bind(pos))
+// UpdateWith returns the same query as Update using the given binding
+// parameter type instead of BindType.
+func (q *QueryBuilder) UpdateWith(bt BindParam) string {
+	return q.withBindType(bt).Update()
 }
 
-// NamedUpdate returns the query to update a record using named values. Update
-// won't update neither the id nor the created_at column.
-func (q *QueryBuilder) NamedUpdate() string {
-	var values []string
-	var idName = q.idColumn()
-	for _, name := range q.Columns {
-		if name != idName && name != createdAtColumn {
-			values = append(values, name+" = :"+name)
-		}
-	}
-	return fmt.Sprintf("UPDATE %s SET %s WHERE %s = :%s", q.Table, join(values), q.idColumn(), idName)
+// DeleteWith returns the same query as Delete using the given binding
+// parameter type instead of BindType.
+func (q *QueryBuilder) DeleteWith(bt BindParam) string {
+	return q.withBindType(bt).Delete()
 }
 
-// Delete returns the query to mark a record as deleted.
-func (q *QueryBuilder) Delete() string {
-	return fmt.Sprintf("UPDATE %s SET deleted_at = %s WHERE %s = %s", q.Table, q.bind(1), q.idColumn(), q.bind(2))
+// withBindType returns a copy of the query builder that uses the given binding
+// parameter type.
+func (q *QueryBuilder) withBindType(bt BindParam) *QueryBuilder {
+	c := *q
+	c.BindType = bt
+	return &c
 }
 
-// HardDelete returns the query to delete a row by id.
-func (q *QueryBuilder) HardDelete() string {
-	return fmt.Sprintf("DELETE FROM %s WHERE %s = %s", q.Table, q.idColumn(), q.bind(1))
+// Select returns the query to get a record by id. The queries by id require a
+// primary key, see HasPrimaryKey.
+func (q *QueryBuilder) Select() string {
+	return q.finish(q.selectByID(true))
 }
 
-func (q *QueryBuilder) idColumn() string {
-	if q.PrimaryKey != "" {
-		return q.PrimaryKey
-	}
-	return idColumn
+// SelectIncludingDeleted returns the query to get a record by id that also
+// returns deleted records regardless of SelectDeleted.
+func (q *QueryBuilder) SelectIncludingDeleted() string {
+	return q.finish(q.selectByID(false))
 }
 
-func (q *QueryBuilder) bind(i int) string {
-	switch q.BindType {
-	case QUESTION:
-		return "?"
+// SelectAsOf returns the query to get a record by id as it was at a given
+// time, using FOR SYSTEM_TIME AS OF. The timestamp is the first binding
+// parameter and the id the second one. It requires a system-versioned table,
+// supported by SQL Server, MariaDB, and PostgreSQL with a temporal tables
+// extension; SQLite and the generic dialect are not supported.
+func (q *QueryBuilder) SelectAsOf() (string, error) {
+	if err := q.keyed("SelectAsOf"); err != nil {
+		return "", err
+	}
+	switch q.dialect() {
+	case POSTGRES, MYSQL, SQLSERVER:
+		return q.finish(fmt.Sprintf("SELECT %s FROM %s FOR SYSTEM_TIME AS OF %s%s", q.columns(), q.Table, q.bind(1), q.where(3, true, q.idColumn()+" = "+q.bindFor(q.idColumn(), 2)))), nil
 	default:
-		return "$" + strconv.Itoa(i)
+		return "", q.unsupported("SelectAsOf")
 	}
 }
 
-func (q *QueryBuilder) columns() string {
-	return strings.Join(q.Columns, ", ")
+// ByIDClause returns the predicates used by Select to get a record by id,
+// without the WHERE keyword, with the id using the binding parameter at
+// position startBind. The tenant predicate, if TenantColumn is set, uses the
+// next parameter, and the deleted records are filtered unless SelectDeleted is
+// true. For example:
+//
+//	"SELECT lower(name) FROM users WHERE " + q.ByIDClause(1)
+func (q *QueryBuilder) ByIDClause(startBind int) string {
+	return q.format(strings.TrimPrefix(q.where(startBind+1, true, q.idColumn()+" = "+q.bindFor(q.idColumn(), startBind)), " WHERE "))
 }
 
-func (q *QueryBuilder) values() string {
-	n := len(q.Columns)
-	c := make([]string, n)
-	for i := 0; i < n; i++ {
-		c[i] = q.bind(i + 1)
+func (q *QueryBuilder) selectByID(softDelete bool) string {
+	return fmt.Sprintf("SELECT %s FROM %s%s", q.columns(), q.Table, q.where(2, softDelete, q.idColumn()+" = "+q.bindFor(q.idColumn(), 1)))
+}
+
+// SelectForShare returns the query to get a record by id that also acquires a
+// shared lock on it, the lock prevents concurrent updates and deletes but not
+// concurrent reads. It uses FOR SHARE in PostgreSQL and LOCK IN SHARE MODE in
+// MySQL, other dialects are not supported.
+func (q *QueryBuilder) SelectForShare() (string, error) {
+	if err := q.keyed("SelectForShare"); err != nil {
+		return "", err
+	}
+	switch q.dialect() {
+	case POSTGRES:
+		return q.finish(q.selectByID(true) + " FOR SHARE"), nil
+	case MYSQL:
+		return q.finish(q.selectByID(true) + " LOCK IN SHARE MODE"), nil
+	default:
+		return "", q.unsupported("SelectForShare")
 	}
-	return join(c)
 }
 
-func (q *QueryBuilder) namedValues() string {
-	n := len(q.Columns)
+// SelectForUpdateOf returns the query to get a record by id that locks only
+// the rows of the given tables, FOR UPDATE OF table. If no tables are given it
+// locks the rows of the query builder table. The tables must be part of the
+// query, and it is only supported by PostgreSQL.
+func (q *QueryBuilder) SelectForUpdateOf(tables ...string) (string, error) {
+	if err := q.keyed("SelectForUpdateOf"); err != nil {
+		return "", err
+	}
+	if q.dialect() != POSTGRES {
+		return "", q.unsupported("SelectForUpdateOf")
+	}
+	if len(tables) == 0 {
+		tables = []string{q.Table}
+	}
+	for _, t := range tables {
+		if t != q.Table {
+			return "", fmt.Errorf("table %q is not part of the query", t)
+		}
+	}
+	return q.finish(q.selectByID(true) + " FOR UPDATE OF " + join(tables)), nil
+}
+
+// SelectBy returns a query to get a record by the given column name.
+func (q *QueryBuilder) SelectBy(name string, extraNames ...string) string {
+	preds := q.byPredicates(name, extraNames)
+	return q.finish(fmt.Sprintf("SELECT %s FROM %s%s", q.columns(), q.Table, q.where(len(preds)+1, true, preds...)))
+}
+
+// ListAndCountBy returns a query to get a page of the records matching the
+// given column names and a query to count all of them. Both queries share the
+// same WHERE clause and arguments, the list query also uses two more binding
+// parameters for the LIMIT and OFFSET values, after the tenant if TenantColumn
+// is set. If OrderByPrimaryKey is set the records are sorted by primary key.
+func (q *QueryBuilder) ListAndCountBy(name string, extraNames ...string) (list, count string) {
+	preds := q.byPredicates(name, extraNames)
+	where := q.where(len(preds)+1, true, preds...)
+	pos := len(preds) + 1
+	if q.TenantColumn != "" {
+		pos++
+	}
+	list = fmt.Sprintf("SELECT %s FROM %s%s", q.columns(), q.Table, where)
+	if q.OrderByPrimaryKey && q.HasPrimaryKey() {
+		list += " ORDER BY " + q.idColumn()
+	}
+	list += " LIMIT " + q.bind(pos) + " OFFSET " + q.bind(pos+1)
+	count = fmt.Sprintf("SELECT COUNT(*) FROM %s%s", q.Table, where)
+	return q.finish(list), q.finish(count)
+}
+
+// CountDistinctBy returns a query to count the distinct values of the column
+// distinctName in the records matching the given column names, like SELECT
+// COUNT(DISTINCT email) FROM users WHERE org_id = $1. The binding parameters
+// start with the given column names.
+func (q *QueryBuilder) CountDistinctBy(distinctName, name string, extraNames ...string) string {
+	preds := q.byPredicates(name, extraNames)
+	return q.finish(fmt.Sprintf("SELECT COUNT(DISTINCT %s) FROM %s%s", distinctName, q.Table, q.where(len(preds)+1, true, preds...)))
+}
+
+// CountEstimate returns a query to get the estimated number of records in the
+// table from the PostgreSQL statistics, that is much faster than COUNT(*) in
+// large tables. The estimate is updated by VACUUM and ANALYZE. The query uses
+// the table name as the only argument, and it is only supported by PostgreSQL.
+func (q *QueryBuilder) CountEstimate() (string, error) {
+	if q.dialect() != POSTGRES {
+		return "", q.unsupported("CountEstimate")
+	}
+	return q.finish("SELECT reltuples::bigint FROM pg_class WHERE relname = " + q.bind(1)), nil
+}
+
+// byPredicates returns the equality predicates for the given column names.
+func (q *QueryBuilder) byPredicates(name string, extraNames []string) []string {
+	preds := []string{name + " = " + q.bindFor(name, 1)}
+	// Append extra names.
+	for i, n := range extraNames {
+		preds = append(preds, n+" = "+q.bindFor(n, i+2))
+	}
+	return preds
+}
+
+// SelectByLower returns a query to get a record by the given column name
+// comparing the values in lowercase, LOWER(name) = LOWER($1). The query can use
+// an expression index on LOWER(name) to implement case-insensitive lookups.
+func (q *QueryBuilder) SelectByLower(name string) string {
+	pred := "LOWER(" + name + ") = LOWER(" + q.bindFor(name, 1) + ")"
+	return q.finish(fmt.Sprintf("SELECT %s FROM %s%s", q.columns(), q.Table, q.where(2, true, pred)))
+}
+
+// SelectByAny returns a query to get the records matching any of the given
+// column names. If no names are given it returns the same query as SelectAll.
+func (q *QueryBuilder) SelectByAny(names ...string) string {
+	if len(names) == 0 {
+		return q.SelectAll()
+	}
+	preds := make([]string, len(names))
+	for i, n := range names {
+		preds[i] = n + " = " + q.bindFor(n, i+1)
+	}
+	anyOf := "(" + strings.Join(preds, " OR ") + ")"
+	return q.finish(fmt.Sprintf("SELECT %s FROM %s%s", q.columns(), q.Table, q.where(len(preds)+1, true, anyOf)))
+}
+
+// Exists returns a query to check if a record with the given id exists. The
+// form of the query depends on the dialect:
+//
+//   - PostgreSQL and SQLite use SELECT EXISTS (SELECT 1 FROM ...), the query
+//     always returns one row with a boolean value.
+//   - MySQL and the generic dialect use SELECT 1 FROM ... LIMIT 1, and SQL
+//     Server uses SELECT TOP 1 1 FROM ..., the query returns one row if the
+//     record exists and no rows, sql.ErrNoRows, if it does not.
+func (q *QueryBuilder) Exists() string {
+	return q.finish(q.exists(q.where(2, true, q.idColumn()+" = "+q.bindFor(q.idColumn(), 1))))
+}
+
+// ExistsBy returns a query to check if a record with the given column names
+// exists. It uses the same form as Exists.
+func (q *QueryBuilder) ExistsBy(name string, extraNames ...string) string {
+	preds := q.byPredicates(name, extraNames)
+	return q.finish(q.exists(q.where(len(preds)+1, true, preds...)))
+}
+
+// exists returns the existence check with the given WHERE clause in the form
+// of the dialect of the query builder.
+func (q *QueryBuilder) exists(where string) string {
+	switch q.dialect() {
+	case POSTGRES, SQLITE:
+		return fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s%s)", q.Table, where)
+	case SQLSERVER:
+		return fmt.Sprintf("SELECT TOP 1 1 FROM %s%s", q.Table, where)
+	default:
+		return fmt.Sprintf("SELECT 1 FROM %s%s LIMIT 1", q.Table, where)
+	}
+}
+
+// SelectWhereNotExists returns a query to get the records without related
+// records in another table, for example, the users without orders:
+//
+//	q.SelectWhereNotExists("orders", "user_id", "id")
+//
+// generates:
+//
+//	SELECT ... FROM users WHERE NOT EXISTS (SELECT 1 FROM orders WHERE orders.user_id = users.id)
+//
+// The columns in the subquery are qualified with the table names. The tenant
+// predicate uses the first binding parameter.
+func (q *QueryBuilder) SelectWhereNotExists(subTable, subCol, selfCol string) string {
+	pred := fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s WHERE %s = %s)", subTable, qualify(subTable, subCol), qualify(q.Table, selfCol))
+	return q.finish(fmt.Sprintf("SELECT %s FROM %s%s", q.columns(), q.Table, q.where(1, true, pred)))
+}
+
+// SelectWhereInSubquery returns a query to get the records where the given
+// column is in the results of a subquery, for example:
+//
+//	q.SelectWhereInSubquery("id", "SELECT user_id FROM orders WHERE created_at > $1")
+//
+// The subquery is not modified and numbering its binding parameters is the
+// responsibility of the caller. If TenantColumn is set, the tenant predicate
+// uses the binding parameter at position 1, so numbered parameters in the
+// subquery must start at 2, while QUESTION parameters are positional and the
+// tenant goes after the subquery arguments.
+func (q *QueryBuilder) SelectWhereInSubquery(col, subquery string) string {
+	pred := col + " IN (" + subquery + ")"
+	return q.finish(fmt.Sprintf("SELECT %s FROM %s%s", q.columns(), q.Table, q.where(1, true, pred)))
+}
+
+// SelectIn returns a query to get the records where the given column is one of
+// n values, name IN ($1, $2, ...). The number of values must be greater than
+// zero.
+func (q *QueryBuilder) SelectIn(name string, n int) string {
+	binds := make([]string, n)
+	for i := range binds {
+		binds[i] = q.bindFor(name, i+1)
+	}
+	pred := name + " IN (" + join(binds) + ")"
+	return q.finish(fmt.Sprintf("SELECT %s FROM %s%s", q.columns(), q.Table, q.where(n+1, true, pred)))
+}
+
+// SelectInChunked returns the queries to get the records where the given
+// column is one of total values, splitting the values in chunks of at most
+// chunkSize values. Each query is like the one returned by SelectIn, with
+// placeholders starting at the first position, and only the last one can have
+// less than chunkSize values. If chunkSize is not positive, a single query is
+// returned. It returns nil if total is not positive.
+//
+// Chunks allow to run queries with more values than the maximum number of
+// parameters supported by the database.
+func (q *QueryBuilder) SelectInChunked(name string, total, chunkSize int) []string {
+	if total <= 0 {
+		return nil
+	}
+	if chunkSize <= 0 || chunkSize > total {
+		chunkSize = total
+	}
+	var queries []string
+	full := q.SelectIn(name, chunkSize)
+	for total >= chunkSize {
+		queries = append(queries, full)
+		total -= chunkSize
+	}
+	if total > 0 {
+		queries = append(queries, q.SelectIn(name, total))
+	}
+	return queries
+}
+
+// SelectAll returns a query to get all entries in a table.
+func (q *QueryBuilder) SelectAll() string {
+	return q.finish(q.selectAll(true))
+}
+
+// SelectSample returns a query to get a random sample of the records. In
+// PostgreSQL it uses TABLESAMPLE SYSTEM, and the first binding parameter is
+// the percentage of the table to sample; the sample is taken before the
+// records are filtered:
+//
+//	SELECT id, name FROM users TABLESAMPLE SYSTEM ($1) WHERE deleted_at IS NULL
+//
+// In SQLite and MySQL it sorts the filtered records randomly, and the last
+// binding parameter is the number of records to return:
+//
+//	SELECT id, name FROM users WHERE deleted_at IS NULL ORDER BY RANDOM() LIMIT ?
+//
+// Other dialects are not supported.
+func (q *QueryBuilder) SelectSample() (string, error) {
+	switch q.dialect() {
+	case POSTGRES:
+		return q.finish(fmt.Sprintf("SELECT %s FROM %s TABLESAMPLE SYSTEM (%s)%s", q.columns(), q.Table, q.bind(1), q.where(2, true))), nil
+	case SQLITE, MYSQL:
+		random := "RANDOM()"
+		if q.dialect() == MYSQL {
+			random = "RAND()"
+		}
+		pos := 1
+		if q.TenantColumn != "" {
+			pos++
+		}
+		return q.finish(fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s LIMIT %s", q.columns(), q.Table, q.where(1, true), random, q.bind(pos))), nil
+	default:
+		return "", q.unsupported("SelectSample")
+	}
+}
+
+// SelectColumnsOrdered returns the query of SelectAll with only the given
+// columns, in the given order instead of the order of the query builder, for
+// example, to match the columns of a covering index or the fields a row is
+// scanned into. It returns an error if a column is not one of the query builder
+// columns or if no columns are given.
+func (q *QueryBuilder) SelectColumnsOrdered(order []string) (string, error) {
+	if len(order) == 0 {
+		return "", fmt.Errorf("SelectColumnsOrdered: %w", ErrNoColumns)
+	}
+	for _, name := range order {
+		if !q.HasColumn(name) {
+			return "", fmt.Errorf("%w %q in table %s", ErrUnknownColumn, name, q.Table)
+		}
+	}
+	c := *q
+	c.Columns = order
+	return q.finish(c.selectAll(true)), nil
+}
+
+// SelectAllIncludingDeleted returns a query to get all entries in a table,
+// including the deleted ones regardless of SelectDeleted.
+func (q *QueryBuilder) SelectAllIncludingDeleted() string {
+	return q.finish(q.selectAll(false))
+}
+
+func (q *QueryBuilder) selectAll(softDelete bool) string {
+	s := fmt.Sprintf("SELECT %s FROM %s%s", q.columns(), q.Table, q.where(1, softDelete))
+	if q.OrderByPrimaryKey && q.HasPrimaryKey() {
+		s += " ORDER BY " + q.idColumn()
+	}
+	return s
+}
+
+// Union returns the query that combines the records of SelectAll with the
+// records of SelectAll in the other query builder, without duplicates:
+//
+//	SELECT id, name FROM users WHERE deleted_at IS NULL UNION SELECT id, name FROM admins WHERE deleted_at IS NULL
+//
+// Each side filters the deleted records and the tenant according to its own
+// query builder, and the binding parameters of the other query builder are
+// numbered after the ones of q, using the BindType of q. The records are not
+// sorted, and it returns an error if the query builders do not have the same
+// number of columns.
+func (q *QueryBuilder) Union(other *QueryBuilder) (string, error) {
+	return q.combine("UNION", other)
+}
+
+// UnionAll returns the query that combines the records of both query builders
+// like Union, but keeping the duplicates.
+func (q *QueryBuilder) UnionAll(other *QueryBuilder) (string, error) {
+	return q.combine("UNION ALL", other)
+}
+
+// Intersect returns the query that gets the records that are both in q and in
+// the other query builder, see Union. MySQL supports it from version 8.0.31.
+func (q *QueryBuilder) Intersect(other *QueryBuilder) (string, error) {
+	return q.combine("INTERSECT", other)
+}
+
+// Except returns the query that gets the records in q that are not in the other
+// query builder, see Union. MySQL supports it from version 8.0.31.
+func (q *QueryBuilder) Except(other *QueryBuilder) (string, error) {
+	return q.combine("EXCEPT", other)
+}
+
+func (q *QueryBuilder) combine(op string, other *QueryBuilder) (string, error) {
+	if len(q.Columns) != len(other.Columns) {
+		return "", fmt.Errorf("cannot combine %d columns of %s with %d columns of %s",
+			len(q.Columns), q.Table, len(other.Columns), other.Table)
+	}
+	pos := 1
+	if q.TenantColumn != "" {
+		pos++
+	}
+	o := *other
+	o.BindType, o.NumberedBinds = q.BindType, q.NumberedBinds
+	return q.finish(fmt.Sprintf("SELECT %s FROM %s%s %s SELECT %s FROM %s%s",
+		q.columns(), q.Table, q.where(1, true), op, o.columns(), o.Table, o.where(pos, true))), nil
+}
+
+// SelectAfter returns a keyset pagination query to get the page of records
+// after a given cursor. The records are sorted by the given column followed by
+// the primary key columns, so the order is deterministic even if the sort
+// column has ties. If sortCol is empty, or one of the primary key columns, the
+// records are sorted only by the primary key. For example, with the created_at
+// column:
+//
+//	SELECT ... FROM users WHERE (created_at, id) > ($1, $2) ORDER BY created_at, id LIMIT $3
+//
+// The arguments are the cursor values, in the same order as the ORDER BY
+// columns, followed by the tenant, if TenantColumn is set, and the limit. Row
+// value comparisons are not supported by SQL Server.
+func (q *QueryBuilder) SelectAfter(sortCol string) (string, error) {
+	if err := q.keyed("SelectAfter"); err != nil {
+		return "", err
+	}
+	if q.dialect() == SQLSERVER {
+		return "", q.unsupported("SelectAfter")
+	}
+	keys := q.primaryKeys()
+	cols := keys
+	if sortCol != "" && indexOf(keys, sortCol) < 0 {
+		if !q.HasColumn(sortCol) {
+			return "", fmt.Errorf("%w %q in table %s", ErrUnknownColumn, sortCol, q.Table)
+		}
+		cols = append([]string{sortCol}, keys...)
+	}
+	binds := make([]string, len(cols))
+	for i, name := range cols {
+		binds[i] = q.bindFor(name, i+1)
+	}
+	pred := "(" + join(cols) + ") > (" + join(binds) + ")"
+	pos := len(cols) + 1
+	if q.TenantColumn != "" {
+		pos++
+	}
+	return q.finish(fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s LIMIT %s",
+		q.columns(), q.Table, q.where(len(cols)+1, true, pred), join(cols), q.bind(pos))), nil
+}
+
+// OrderBy returns a query to get all entries in a table sorted by the given
+// columns. Each column can be followed by ASC or DESC, for example "name DESC".
+// It returns an error if a column is not one of the query builder columns or
+// the direction is not valid, use OrderByRaw to sort by other expressions.
+func (q *QueryBuilder) OrderBy(cols ...string) (string, error) {
+	if len(cols) == 0 {
+		return "", fmt.Errorf("OrderBy: %w", ErrNoColumns)
+	}
+	for _, c := range cols {
+		fields := strings.Fields(c)
+		if len(fields) == 0 || len(fields) > 2 || !q.HasColumn(fields[0]) {
+			return "", fmt.Errorf("%w %q in table %s", ErrUnknownColumn, c, q.Table)
+		}
+		if len(fields) == 2 && !strings.EqualFold(fields[1], "ASC") && !strings.EqualFold(fields[1], "DESC") {
+			return "", fmt.Errorf("invalid order direction in %q", c)
+		}
+	}
+	return q.OrderByRaw(join(cols)), nil
+}
+
+// OrderByRaw returns a query to get all entries in a table sorted by the given
+// expression, like "LENGTH(name)" or "random()". The expression is not
+// validated nor escaped, it must never contain user input.
+func (q *QueryBuilder) OrderByRaw(expr string) string {
+	return q.finish(fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s", q.columns(), q.Table, q.where(1, true), expr))
+}
+
+// OrderColumn is a column of an ORDER BY clause and its direction.
+type OrderColumn struct {
+	Name string
+	Desc bool
+}
+
+// String returns the column as written in an ORDER BY clause, "name" or "name
+// DESC".
+func (c OrderColumn) String() string {
+	if c.Desc {
+		return c.Name + " DESC"
+	}
+	return c.Name
+}
+
+// SelectTopWithTies returns a query to get the first n records sorted by the
+// given columns, including the records that tie with the last one, for example,
+// to get the leaders of a ranking:
+//
+//	SELECT id, name, score FROM users WHERE deleted_at IS NULL ORDER BY score DESC FETCH FIRST 3 ROWS WITH TIES
+//
+// It uses TOP (n) WITH TIES in SQL Server, and it is only supported by
+// PostgreSQL, version 13 or later, and SQL Server. It returns an error if n is
+// not positive, there are no columns, or a column is not one of the query
+// builder columns.
+func (q *QueryBuilder) SelectTopWithTies(n int, orderBy ...OrderColumn) (string, error) {
+	d := q.dialect()
+	if d != POSTGRES && d != SQLSERVER {
+		return "", q.unsupported("SelectTopWithTies")
+	}
+	if n <= 0 {
+		return "", fmt.Errorf("invalid number of records %d", n)
+	}
+	if len(orderBy) == 0 {
+		return "", fmt.Errorf("SelectTopWithTies: %w", ErrNoColumns)
+	}
+	cols := make([]string, len(orderBy))
+	for i, c := range orderBy {
+		if !q.HasColumn(c.Name) {
+			return "", fmt.Errorf("%w %q in table %s", ErrUnknownColumn, c.Name, q.Table)
+		}
+		cols[i] = c.String()
+	}
+	if d == SQLSERVER {
+		return q.finish(fmt.Sprintf("SELECT TOP (%d) WITH TIES %s FROM %s%s ORDER BY %s",
+			n, q.columns(), q.Table, q.where(1, true), join(cols))), nil
+	}
+	return q.finish(fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s FETCH FIRST %d ROWS WITH TIES",
+		q.columns(), q.Table, q.where(1, true), join(cols), n)), nil
+}
+
+// Insert returns the query to insert a record.
+func (q *QueryBuilder) Insert() string {
+	q.mustWrite("Insert")
+	return q.finish(q.insert())
+}
+
+func (q *QueryBuilder) insert() string {
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", q.Table, q.columns(), q.values())
+}
+
+// InsertFromSelect returns the query to insert the records returned by the
+// given select query, INSERT INTO table (cols) SELECT ... The columns default
+// to the query builder columns. The binding parameters of the select query
+// are not modified, numbering them is the responsibility of the caller.
+func (q *QueryBuilder) InsertFromSelect(selectSQL string, cols ...string) string {
+	q.mustWrite("InsertFromSelect")
+	if len(cols) == 0 {
+		cols = q.Columns
+	}
+	return q.finish(fmt.Sprintf("INSERT INTO %s (%s) %s", q.Table, join(cols), selectSQL))
+}
+
+// InsertWithReturning returns the query to insert that returns the id. The
+// RETURNING clause is omitted in dialects that do not support it, see
+// SupportsReturning; in those the id must be obtained using the
+// sql.Result.LastInsertId method. It is also omitted if the table has no
+// primary key, see HasPrimaryKey.
+func (q *QueryBuilder) InsertWithReturning() string {
+	q.mustWrite("InsertWithReturning")
+	return q.finish(q.returningKey(q.insertWithoutKey()))
+}
+
+// InsertReturningColumn returns the query to insert a record that returns the
+// given column, like a generated sort order. Like InsertWithReturning, the
+// primary key is not inserted and it uses the same arguments. It returns an
+// error if the column is not one of the query builder columns, and it is only
+// supported by the dialects with RETURNING, see SupportsReturning.
+func (q *QueryBuilder) InsertReturningColumn(col string) (string, error) {
+	if err := q.writable("InsertReturningColumn"); err != nil {
+		return "", err
+	}
+	if !q.SupportsReturning() {
+		return "", q.unsupported("InsertReturningColumn")
+	}
+	if !q.HasColumn(col) {
+		return "", fmt.Errorf("%w %q in table %s", ErrUnknownColumn, col, q.Table)
+	}
+	return q.finish(q.insertWithoutKey() + " RETURNING " + col), nil
+}
+
+// insertWithoutKey returns the query to insert a record without the primary
+// key.
+func (q *QueryBuilder) insertWithoutKey() string {
+	var pos = 1
+	var idName = q.idColumn()
+	var columns, values []string
+	for _, name := range q.Columns {
+		if name != idName {
+			v, ok := q.valueExpr(name, q.bindFor(name, pos))
+			if ok {
+				pos++
+			}
+			columns = append(columns, name)
+			values = append(values, v)
+		}
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", q.Table, join(columns), join(values))
+}
+
+// InsertReturningAll returns the query to insert a record that returns all the
+// columns of the record, it uses the same arguments as Insert. It is only
+// supported by the dialects with RETURNING, see SupportsReturning.
+func (q *QueryBuilder) InsertReturningAll() (string, error) {
+	if err := q.writable("InsertReturningAll"); err != nil {
+		return "", err
+	}
+	if !q.SupportsReturning() {
+		return "", q.unsupported("InsertReturningAll")
+	}
+	return q.finish(q.insert() + " RETURNING " + q.returningAll()), nil
+}
+
+// InsertReturning returns the query to insert a record, including the primary
+// key, that returns the given columns, for example, the created_at column
+// generated by the database in tables with client-generated ids. It uses the
+// same arguments as Insert. It returns an error if a column is not one of the
+// query builder columns, and it is only supported by the dialects with
+// RETURNING, see SupportsReturning.
+func (q *QueryBuilder) InsertReturning(cols ...string) (string, error) {
+	if err := q.writable("InsertReturning"); err != nil {
+		return "", err
+	}
+	if !q.SupportsReturning() {
+		return "", q.unsupported("InsertReturning")
+	}
+	if len(cols) == 0 {
+		return "", fmt.Errorf("InsertReturning: %w", ErrNoColumns)
+	}
+	for _, name := range cols {
+		if !q.HasColumn(name) {
+			return "", fmt.Errorf("%w %q in table %s", ErrUnknownColumn, name, q.Table)
+		}
+	}
+	return q.finish(q.insert() + " RETURNING " + join(cols)), nil
+}
+
+// InsertDefaultsReturningAll returns the query to insert a record without the
+// given columns, that get the default values of the database, that returns all
+// the columns of the record, including the generated ones. The arguments are
+// the values of the inserted columns, in the order of the query builder
+// columns. It returns an error if a skipped column is not one of the query
+// builder columns or if all the columns are skipped, and it is only supported
+// by the dialects with RETURNING, see SupportsReturning.
+func (q *QueryBuilder) InsertDefaultsReturningAll(skip ...string) (string, error) {
+	if err := q.writable("InsertDefaultsReturningAll"); err != nil {
+		return "", err
+	}
+	if !q.SupportsReturning() {
+		return "", q.unsupported("InsertDefaultsReturningAll")
+	}
+	for _, name := range skip {
+		if !q.HasColumn(name) {
+			return "", fmt.Errorf("%w %q in table %s", ErrUnknownColumn, name, q.Table)
+		}
+	}
+	var columns []string
+	for _, name := range q.Columns {
+		if indexOf(skip, name) < 0 {
+			columns = append(columns, name)
+		}
+	}
+	if len(columns) == 0 {
+		return "", fmt.Errorf("InsertDefaultsReturningAll: %w", ErrNoColumns)
+	}
+	return q.finish(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING %s",
+		q.Table, join(columns), q.valuesOf(columns, 1), q.returningAll())), nil
+}
+
+// InsertDefaultValues returns the query to insert a record with the default
+// values of all the columns, for example, to reserve the next id of a
+// sequence, and it does not have arguments. In the dialects with RETURNING it
+// returns the id of the record:
+//
+//	INSERT INTO users DEFAULT VALUES RETURNING id
+//
+// MySQL does not support DEFAULT VALUES and uses INSERT INTO users () VALUES
+// (), and in MySQL, SQL Server, and the generic dialect the id must be obtained
+// with sql.Result.LastInsertId.
+func (q *QueryBuilder) InsertDefaultValues() string {
+	q.mustWrite("InsertDefaultValues")
+	if q.dialect() == MYSQL {
+		return q.finish("INSERT INTO " + q.Table + " () VALUES ()")
+	}
+	return q.finish(q.returningKey("INSERT INTO " + q.Table + " DEFAULT VALUES"))
+}
+
+// Insert returns the query to insert a record using named values.
+func (q *QueryBuilder) NamedInsert() string {
+	q.mustWrite("NamedInsert")
+	return q.finish(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", q.Table, q.columns(), q.namedValues()))
+}
+
+// NamedInsertWithReturning returns the query to insert a record using named
+// values, the query will return the id. Like InsertWithReturning, the
+// RETURNING clause is omitted in dialects that do not support it and in tables
+// without a primary key.
+func (q *QueryBuilder) NamedInsertWithReturning() string {
+	q.mustWrite("NamedInsertWithReturning")
+	var idName = q.idColumn()
+	var columns, values []string
+	for _, name := range q.Columns {
+		if name != idName {
+			v, _ := q.valueExpr(name, q.named(name))
+			columns = append(columns, name)
+			values = append(values, v)
+		}
+	}
+	return q.finish(q.returningKey(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", q.Table, join(columns), join(values))))
+}
+
+// returningAll returns the list of columns used in the RETURNING clause of
+// the queries that return all the columns, or * if ReturningStar is set.
+func (q *QueryBuilder) returningAll() string {
+	if q.ReturningStar {
+		return "*"
+	}
+	if len(q.ReturningOrder) > 0 {
+		return join(q.ReturningOrder)
+	}
+	return q.columns()
+}
+
+// returning appends the RETURNING clause with the given columns to the query
+// if the dialect supports it.
+func (q *QueryBuilder) returning(s, columns string) string {
+	if !q.SupportsReturning() {
+		return s
+	}
+	return s + " RETURNING " + columns
+}
+
+// returningKey appends the RETURNING clause with the primary key to the query
+// if the dialect supports it and the table has a primary key.
+func (q *QueryBuilder) returningKey(s string) string {
+	if !q.HasPrimaryKey() {
+		return s
+	}
+	return q.returning(s, q.idColumn())
+}
+
+// Update returns the query to update a record. Update won't update neither the
+// id nor the created_at column.
+func (q *QueryBuilder) Update() string {
+	q.mustWrite("Update")
+	return q.finish(q.update(q.updatableColumns()))
+}
+
+// UpdateReturningAll returns the query to update a record that returns all the
+// columns of the record, it uses the same arguments as Update. It is only
+// supported by the dialects with RETURNING, see SupportsReturning.
+func (q *QueryBuilder) UpdateReturningAll() (string, error) {
+	if err := q.writable("UpdateReturningAll"); err != nil {
+		return "", err
+	}
+	if err := q.keyed("UpdateReturningAll"); err != nil {
+		return "", err
+	}
+	if !q.SupportsReturning() {
+		return "", q.unsupported("UpdateReturningAll")
+	}
+	return q.finish(q.update(q.updatableColumns()) + " RETURNING " + q.returningAll()), nil
+}
+
+// UpdateColumnIf returns the query to update a column of a record by id only if
+// another column has an expected value, for example, to implement state
+// transitions:
+//
+//	UPDATE users SET status = $1 WHERE id = $2 AND status = $3
+//
+// The new value is the first binding parameter, the id the second one, and the
+// expected value the third one. The number of affected rows is zero if the
+// record did not have the expected value. If VersionColumn is set the version is
+// incremented but not checked. It returns an error if a column is not one of
+// the query builder columns, or if the updated column is not updatable, like
+// the primary key, created_at, or the version column.
+func (q *QueryBuilder) UpdateColumnIf(setCol, condCol string) (string, error) {
+	if err := q.writable("UpdateColumnIf"); err != nil {
+		return "", err
+	}
+	if err := q.keyed("UpdateColumnIf"); err != nil {
+		return "", err
+	}
+	for _, name := range []string{setCol, condCol} {
+		if !q.HasColumn(name) {
+			return "", fmt.Errorf("%w %q in table %s", ErrUnknownColumn, name, q.Table)
+		}
+	}
+	if !q.isUpdatable(setCol, q.idColumn()) {
+		return "", fmt.Errorf("%w %q in table %s: the column is not updatable", ErrUnknownColumn, setCol, q.Table)
+	}
+	set := join(q.appendVersion([]string{setCol + " = " + q.bindFor(setCol, 1)}, ""))
+	preds := []string{
+		q.idColumn() + " = " + q.bindFor(q.idColumn(), 2),
+		condCol + " = " + q.bindFor(condCol, 3),
+	}
+	return q.finish(fmt.Sprintf("UPDATE %s SET %s%s", q.Table, set, q.where(4, false, preds...))), nil
+}
+
+// Increment returns the query to atomically add a value to a numeric column of
+// a record by id, like a counter, without reading it first:
+//
+//	UPDATE users SET login_count = login_count + $1 WHERE id = $2
+//
+// The value is the first binding parameter and the id the second one; a
+// negative value decrements the column. If VersionColumn is set the version is
+// incremented but not checked. It returns an error if the column is not one of
+// the query builder columns or if it is not updatable, like the primary key,
+// created_at, or the version column.
+func (q *QueryBuilder) Increment(col string) (string, error) {
+	if err := q.writable("Increment"); err != nil {
+		return "", err
+	}
+	if err := q.keyed("Increment"); err != nil {
+		return "", err
+	}
+	switch {
+	case !q.HasColumn(col):
+		return "", fmt.Errorf("%w %q in table %s", ErrUnknownColumn, col, q.Table)
+	case !q.isUpdatable(col, q.idColumn()):
+		return "", fmt.Errorf("%w %q in table %s: the column is not updatable", ErrUnknownColumn, col, q.Table)
+	}
+	set := q.appendVersion([]string{col + " = " + col + " + " + q.bindFor(col, 1)}, "")
+	return q.finish(fmt.Sprintf("UPDATE %s SET %s%s", q.Table, join(set),
+		q.where(3, false, q.idColumn()+" = "+q.bindFor(q.idColumn(), 2)))), nil
+}
+
+// ClaimNext returns the query used by queue workers to atomically claim the next
+// pending record, changing its status and returning it:
+//
+//	UPDATE jobs SET status = $1 WHERE id = (SELECT id FROM jobs WHERE status = $2 AND deleted_at IS NULL ORDER BY created_at LIMIT 1 FOR UPDATE SKIP LOCKED) RETURNING id, status, created_at
+//
+// The new status is the first binding parameter and the pending status the
+// second one. SKIP LOCKED makes concurrent workers skip the records already
+// being claimed instead of waiting for them, and no rows are returned if there
+// are no pending records. If VersionColumn is set, the version of the claimed
+// record is incremented. It is only supported by PostgreSQL, and it returns an
+// error if a column is not one of the query builder columns or if the status
+// column is not updatable.
+func (q *QueryBuilder) ClaimNext(statusCol, orderCol string) (string, error) {
+	if err := q.writable("ClaimNext"); err != nil {
+		return "", err
+	}
+	if err := q.keyed("ClaimNext"); err != nil {
+		return "", err
+	}
+	if q.dialect() != POSTGRES {
+		return "", q.unsupported("ClaimNext")
+	}
+	for _, name := range []string{statusCol, orderCol} {
+		if !q.HasColumn(name) {
+			return "", fmt.Errorf("%w %q in table %s", ErrUnknownColumn, name, q.Table)
+		}
+	}
+	id := q.idColumn()
+	if !q.isUpdatable(statusCol, id) {
+		return "", fmt.Errorf("%w %q in table %s: the column is not updatable", ErrUnknownColumn, statusCol, q.Table)
+	}
+	set := q.appendVersion([]string{statusCol + " = " + q.bindFor(statusCol, 1)}, "")
+	sub := fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s LIMIT 1 FOR UPDATE SKIP LOCKED",
+		id, q.Table, q.where(3, true, statusCol+" = "+q.bindFor(statusCol, 2)), orderCol)
+	return q.finish(fmt.Sprintf("UPDATE %s SET %s WHERE %s = (%s) RETURNING %s",
+		q.Table, join(set), id, sub, q.returningAll())), nil
+}
+
+// UpdateSetNull returns the query to set the given columns of a record by id to
+// NULL, for example, to unassign a record:
+//
+//	UPDATE users SET assignee_id = NULL WHERE id = $1
+//
+// The id is the only argument, followed by the version and tenant if
+// VersionColumn or TenantColumn are set. It returns an error if a column is
+// not one of the query builder columns.
+func (q *QueryBuilder) UpdateSetNull(cols ...string) (string, error) {
+	return q.updateSetNull("UpdateSetNull", nil, cols)
+}
+
+// UpdateColumnsSetNull returns the query to update the given columns of a record
+// by id and set the nullCols to NULL, like:
+//
+//	UPDATE users SET status = $1, assignee_id = NULL WHERE id = $2
+//
+// The arguments are the values of the columns followed by the id, like in
+// Update. It returns an error if a column is not one of the query builder
+// columns, if it is not updatable, like the primary key, created_at, or the
+// version column, or if it is given more than once.
+func (q *QueryBuilder) UpdateColumnsSetNull(columns, nullCols []string) (string, error) {
+	return q.updateSetNull("UpdateColumnsSetNull", columns, nullCols)
+}
+
+func (q *QueryBuilder) updateSetNull(method string, columns, nullCols []string) (string, error) {
+	if err := q.writable(method); err != nil {
+		return "", err
+	}
+	if err := q.keyed(method); err != nil {
+		return "", err
+	}
+	if len(nullCols) == 0 {
+		return "", fmt.Errorf("%s: %w", method, ErrNoColumns)
+	}
+	idName := q.idColumn()
+	seen := make(map[string]bool, len(columns)+len(nullCols))
+	for _, cols := range [][]string{columns, nullCols} {
+		for _, name := range cols {
+			switch {
+			case !q.HasColumn(name):
+				return "", fmt.Errorf("%w %q in table %s", ErrUnknownColumn, name, q.Table)
+			case !q.isUpdatable(name, idName):
+				return "", fmt.Errorf("%w %q in table %s: the column is not updatable", ErrUnknownColumn, name, q.Table)
+			case seen[name]:
+				return "", fmt.Errorf("%w %q in table %s: the column is set more than once", ErrUnknownColumn, name, q.Table)
+			}
+			seen[name] = true
+		}
+	}
+	return q.finish(q.update(columns, nullCols...)), nil
+}
+
+// update returns the query to update the given columns of a record by id, and
+// to set the nullCols to NULL.
+func (q *QueryBuilder) update(columns []string, nullCols ...string) string {
+	var set string
+	if len(columns) == 1 && len(nullCols) == 0 && q.VersionColumn == "" {
+		set = columns[0] + " = " + q.bindFor(columns[0], 1)
+	} else {
+		v := make([]string, len(columns), len(columns)+len(nullCols)+1)
+		for i, name := range columns {
+			v[i] = name + " = " + q.bindFor(name, i+1)
+		}
+		for _, name := range nullCols {
+			v = append(v, name+" = NULL")
+		}
+		if q.VersionColumn != "" {
+			v = append(v, q.VersionColumn+" = "+q.VersionColumn+" + 1")
+		}
+		set = join(v)
+	}
+	pos := len(columns) + 1
+	preds := []string{q.idColumn() + " = " + q.bindFor(q.idColumn(), pos)}
+	if q.VersionColumn != "" {
+		pos++
+		preds = append(preds, q.VersionColumn+" = "+q.bindFor(q.VersionColumn, pos))
+	}
+	return fmt.Sprintf("UPDATE %s SET %s%s", q.Table, set, q.where(pos+1, false, preds...))
+}
+
+// NamedUpdate returns the query to update a record using named values. Update
+// won't update neither the id nor the created_at column.
+func (q *QueryBuilder) NamedUpdate() string {
+	q.mustWrite("NamedUpdate")
+	var values []string
+	var idName = q.idColumn()
+	for _, name := range q.Columns {
+		if q.isUpdatable(name, idName) {
+			values = append(values, name+" = "+q.named(name))
+		}
+	}
+	preds := []string{idName + " = " + q.named(idName)}
+	if q.VersionColumn != "" {
+		values = append(values, q.VersionColumn+" = "+q.VersionColumn+" + 1")
+		preds = append(preds, q.VersionColumn+" = "+q.named(q.VersionColumn))
+	}
+	return q.finish(fmt.Sprintf("UPDATE %s SET %s%s", q.Table, join(values), q.namedWhere(preds...)))
+}
+
+// UpdateFrom returns the query to update records with the values of the
+// matching records in another table, for example:
+//
+//	UPDATE users SET status = src.status FROM staging src WHERE users.id = src.id
+//
+// The source table can include an alias. If no columns are given, it updates
+// the same columns as Update. If TenantColumn is set, the tenant is not
+// updated and the tenant predicate uses the first binding parameter. If
+// VersionColumn is set, the version is incremented. It returns an error if a
+// column is not updatable, like the primary key, created_at, the version, or
+// the tenant column. Qualified column names, like data.email, cannot
+// be read from the source table and return an error.
+//
+// UpdateFrom is only supported by PostgreSQL and SQLite, MySQL and SQL Server
+// use a different syntax for multi-table updates.
+func (q *QueryBuilder) UpdateFrom(sourceTable string, setCols []string, joinCol string) (string, error) {
+	if err := q.writable("UpdateFrom"); err != nil {
+		return "", err
+	}
+	switch q.dialect() {
+	case POSTGRES, SQLITE:
+	default:
+		return "", q.unsupported("UpdateFrom")
+	}
+	if len(setCols) == 0 {
+		for _, name := range q.updatableColumns() {
+			if name != q.TenantColumn {
+				setCols = append(setCols, name)
+			}
+		}
+	}
+	for _, name := range append([]string{joinCol}, setCols...) {
+		if !q.HasColumn(name) {
+			return "", fmt.Errorf("%w %q in table %s", ErrUnknownColumn, name, q.Table)
+		}
+	}
+	for _, name := range setCols {
+		if !q.isUpdatable(name, q.idColumn()) || name == q.TenantColumn {
+			return "", fmt.Errorf("%w %q in table %s: the column is not updatable", ErrUnknownColumn, name, q.Table)
+		}
+	}
+	if err := q.unqualified("UpdateFrom", append([]string{joinCol}, setCols...)); err != nil {
+		return "", err
+	}
+	src := sourceTable
+	if fields := strings.Fields(sourceTable); len(fields) > 0 {
+		src = fields[len(fields)-1]
+	}
+	v := make([]string, len(setCols))
+	for i, name := range setCols {
+		v[i] = name + " = " + qualify(src, name)
+	}
+	v = q.appendVersion(v, q.Table)
+	pred := qualify(q.Table, joinCol) + " = " + qualify(src, joinCol)
+	if q.TenantColumn != "" {
+		pred += " AND " + qualify(q.Table, q.TenantColumn) + " = " + q.bindFor(q.TenantColumn, 1)
+	}
+	return q.finish(fmt.Sprintf("UPDATE %s SET %s FROM %s WHERE %s", q.Table, join(v), sourceTable, pred)), nil
+}
+
+// UpsertOption is the type used to pass options to Upsert.
+type UpsertOption func(o *upsertOptions)
+
+type upsertOptions struct {
+	newerColumn    string
+	returnInserted bool
+}
+
+// OnlyIfNewer makes the upsert update the existing record only if the value of
+// the given column, usually a timestamp like updated_at, is greater in the
+// inserted record than in the existing one. It can be used to implement
+// last-write-wins synchronization without overwriting fresher data.
+func OnlyIfNewer(col string) UpsertOption {
+	return func(o *upsertOptions) {
+		o.newerColumn = col
+	}
+}
+
+// ReturnInserted makes the upsert return the columns of the record followed by
+// the boolean column "inserted", that is true if the record was inserted and
+// false if an existing record was updated. It uses the PostgreSQL system column
+// xmax, that is 0 in newly inserted rows, and it is only supported by
+// PostgreSQL.
+func ReturnInserted() UpsertOption {
+	return func(o *upsertOptions) {
+		o.returnInserted = true
+	}
+}
+
+// Upsert returns the query to insert a record or, if a record with the same
+// primary key exists, update it with the inserted values. It uses the same
+// arguments as Insert. The primary key and the created_at column are not
+// updated. If TenantColumn is set, the tenant is not updated either, and a
+// record of another tenant with the same primary key is neither updated nor
+// inserted. If VersionColumn is set, the version of an updated record is
+// incremented. It uses INSERT ... ON CONFLICT and it is only supported by
+// PostgreSQL and SQLite. It returns an error if an updated column is a
+// qualified name, like data.email.
+func (q *QueryBuilder) Upsert(opts ...UpsertOption) (string, error) {
+	return q.upsert("Upsert", 1, opts)
+}
+
+// UpsertMany returns the query to insert or update the given number of records
+// in a single statement, like Upsert with multiple VALUES tuples. The arguments
+// are the arguments of Insert for each record, one after the other. The records
+// must have different primary keys, PostgreSQL fails if a record is updated
+// twice by the same statement. It is only supported by PostgreSQL and SQLite.
+func (q *QueryBuilder) UpsertMany(rows int, opts ...UpsertOption) (string, error) {
+	return q.upsert("UpsertMany", rows, opts)
+}
+
+func (q *QueryBuilder) upsert(method string, rows int, opts []UpsertOption) (string, error) {
+	if err := q.writable(method); err != nil {
+		return "", err
+	}
+	if err := q.keyed(method); err != nil {
+		return "", err
+	}
+	switch q.dialect() {
+	case POSTGRES, SQLITE:
+	default:
+		return "", q.unsupported(method)
+	}
+	if rows < 1 {
+		return "", fmt.Errorf("%s: invalid number of rows %d", method, rows)
+	}
+	o := new(upsertOptions)
+	for _, fn := range opts {
+		fn(o)
+	}
+	if err := q.unqualified(method, append(q.updatableColumns(), o.newerColumn, q.TenantColumn)); err != nil {
+		return "", err
+	}
+	var set []string
+	for _, name := range q.updatableColumns() {
+		if name != q.TenantColumn {
+			set = append(set, name+" = EXCLUDED."+name)
+		}
+	}
+	set = q.appendVersion(set, q.Table)
+	n := q.valueBinds()
+	tuples := make([]string, rows)
+	for i := range tuples {
+		tuples[i] = q.ValuesTuple(1 + i*n)
+	}
+	s := fmt.Sprintf("INSERT INTO %s (%s)%sON CONFLICT (%s) DO UPDATE SET %s",
+		q.Table, q.columns(), q.valuesClause(tuples), q.idColumn(), join(set))
+	var preds []string
+	if o.newerColumn != "" {
+		if !q.HasColumn(o.newerColumn) {
+			return "", fmt.Errorf("%w %q in table %s", ErrUnknownColumn, o.newerColumn, q.Table)
+		}
+		preds = append(preds, fmt.Sprintf("EXCLUDED.%s > %s", o.newerColumn, qualify(q.Table, o.newerColumn)))
+	}
+	if q.TenantColumn != "" {
+		preds = append(preds, qualify(q.Table, q.TenantColumn)+" = EXCLUDED."+q.TenantColumn)
+	}
+	if len(preds) > 0 {
+		s += " WHERE " + strings.Join(preds, " AND ")
+	}
+	if o.returnInserted {
+		if q.dialect() != POSTGRES {
+			return "", q.unsupported("ReturnInserted")
+		}
+		s += " RETURNING " + q.returningAll() + ", (xmax = 0) AS inserted"
+	}
+	return q.finish(s), nil
+}
+
+// UpsertPortable returns a pair of queries that implement an upsert without
+// native conflict handling, for databases like old SQLite versions that do not
+// support ON CONFLICT.
+//
+// The first query is the same as Update, the second one inserts the record only
+// if there is no record with the same id. Both queries must be executed in the
+// same transaction, the update first and then the insert; the insert uses the
+// same arguments as Insert followed by the id, and the tenant if TenantColumn is
+// set.
+func (q *QueryBuilder) UpsertPortable() (string, string) {
+	q.mustWrite("UpsertPortable")
+	n := q.valueBinds()
+	insert := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s WHERE NOT EXISTS (SELECT 1 FROM %s%s)",
+		q.Table, q.columns(), q.values(), q.Table, q.where(n+2, false, q.idColumn()+" = "+q.bindFor(q.idColumn(), n+1)))
+	return q.Update(), q.finish(insert)
+}
+
+// Merge returns a MERGE statement that inserts a record or, if a record with
+// the same primary key exists, updates it, for the databases without INSERT
+// ... ON CONFLICT. The record is the source of the statement with the given
+// alias, src if empty, and it uses the same arguments as Insert. The primary
+// key and the created_at column are not updated, and if TenantColumn is set the
+// records are also matched by tenant. If VersionColumn is set, the version of
+// an updated record is incremented. It returns an error if a column is a
+// qualified name, like data.email.
+//
+// It uses the SQL Server syntax, that is also supported by PostgreSQL 15 and
+// later, and it is only supported by SQL Server and PostgreSQL.
+func (q *QueryBuilder) Merge(sourceAlias string) (string, error) {
+	if err := q.writable("Merge"); err != nil {
+		return "", err
+	}
+	if err := q.keyed("Merge"); err != nil {
+		return "", err
+	}
+	switch q.dialect() {
+	case SQLSERVER, POSTGRES:
+	default:
+		return "", q.unsupported("Merge")
+	}
+	if err := q.unqualified("Merge", q.Columns); err != nil {
+		return "", err
+	}
+	if sourceAlias == "" {
+		sourceAlias = "src"
+	}
+	on := qualify(q.Table, q.idColumn()) + " = " + qualify(sourceAlias, q.idColumn())
+	if q.TenantColumn != "" {
+		on += " AND " + qualify(q.Table, q.TenantColumn) + " = " + qualify(sourceAlias, q.TenantColumn)
+	}
+	columns := q.updatableColumns()
+	set := make([]string, len(columns))
+	for i, name := range columns {
+		set[i] = name + " = " + qualify(sourceAlias, name)
+	}
+	set = q.appendVersion(set, q.Table)
+	s := fmt.Sprintf("MERGE INTO %s USING (VALUES (%s)) AS %s (%s) ON %s WHEN MATCHED THEN UPDATE SET %s WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s)",
+		q.Table, q.values(), sourceAlias, q.columns(), on, join(set), q.columns(), q.qualifiedColumns(sourceAlias))
+	if q.dialect() == SQLSERVER {
+		// SQL Server requires MERGE statements to be terminated by a semicolon.
+		s += ";"
+	}
+	return q.finish(s), nil
+}
+
+// Delete returns the query to mark a record as deleted. The deleted_at value
+// is the first binding parameter and the id the second one. If SoftDeleteBool is
+// set, the column is set to TRUE, and if DeletedAtNow is set, to the current
+// timestamp; in both cases the id is the first binding parameter.
+func (q *QueryBuilder) Delete() string {
+	q.mustWrite("Delete")
+	return q.finish(q.delete())
+}
+
+func (q *QueryBuilder) delete() string {
+	col := q.deletedColumn()
+	value := q.deletedValue(q.bindFor(col, 1))
+	pos := q.deleteIDPos()
+	return fmt.Sprintf("UPDATE %s SET %s = %s%s", q.Table, col, value, q.where(pos+1, false, q.idColumn()+" = "+q.bindFor(q.idColumn(), pos)))
+}
+
+// deletedValue returns the value that marks a record as deleted: TRUE if
+// SoftDeleteBool is set, the current timestamp if DeletedAtNow is set, or the
+// given parameter otherwise.
+func (q *QueryBuilder) deletedValue(param string) string {
+	switch {
+	case q.SoftDeleteBool:
+		return q.boolLiteral(true)
+	case q.DeletedAtNow:
+		return q.nowExpr()
+	default:
+		return param
+	}
+}
+
+// deleteIDPos returns the position of the id binding parameter in Delete, after
+// the deleted_at value if it is an argument.
+func (q *QueryBuilder) deleteIDPos() int {
+	if q.SoftDeleteBool || q.DeletedAtNow {
+		return 1
+	}
+	return 2
+}
+
+// PrimaryKeyBindPositions returns the position, starting at 1, of the primary
+// key binding parameter in the queries by id, indexed by the name of the method
+// that generates them: Select, Update, Delete, Restore, and HardDelete. For
+// example, the id is the first parameter in Select, the one after the updated
+// columns in Update, and the one after the deleted_at value in Delete.
+func (q *QueryBuilder) PrimaryKeyBindPositions() map[string]int {
+	return map[string]int{
+		"Select":     1,
+		"Update":     len(q.updatableColumns()) + 1,
+		"Delete":     q.deleteIDPos(),
+		"Restore":    1,
+		"HardDelete": 1,
+	}
+}
+
+// CascadeDelete returns the query to mark as deleted the records of a child
+// table that reference a record of the query builder table, to run together
+// with Delete:
+//
+//	UPDATE orders SET deleted_at = $1 WHERE user_id = $2
+//
+// The child table must use the same soft-delete column. Like Delete, the
+// deleted_at value is the first binding parameter and the parent id the second
+// one, and if SoftDeleteBool or DeletedAtNow are set, the column is set to TRUE
+// or the current timestamp and the parent id is the first binding parameter.
+// TenantColumn is not applied to the child table.
+func (q *QueryBuilder) CascadeDelete(childTable, fkCol string) string {
+	q.mustWrite("CascadeDelete")
+	col := q.deletedColumn()
+	return q.finish(fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s = %s",
+		childTable, col, q.deletedValue(q.bindFor(col, 1)), fkCol, q.bind(q.deleteIDPos())))
+}
+
+// Restore returns the query to restore a deleted record by id, setting
+// deleted_at to NULL, or to FALSE if SoftDeleteBool is set.
+func (q *QueryBuilder) Restore() string {
+	q.mustWrite("Restore")
+	value := "NULL"
+	if q.SoftDeleteBool {
+		value = q.boolLiteral(false)
+	}
+	return q.finish(fmt.Sprintf("UPDATE %s SET %s = %s%s", q.Table, q.deletedColumn(), value, q.where(2, false, q.idColumn()+" = "+q.bindFor(q.idColumn(), 1))))
+}
+
+// DeleteIfNotDeleted returns the query to mark a record as deleted only if it
+// is not already deleted, preserving the original deletion time. It uses the
+// same arguments as Delete, and the number of affected rows is zero if the
+// record was already deleted.
+func (q *QueryBuilder) DeleteIfNotDeleted() string {
+	q.mustWrite("DeleteIfNotDeleted")
+	return q.finish(q.delete() + " AND " + q.notDeleted())
+}
+
+// DeleteWithReturning returns the query to mark a record as deleted that
+// returns all the columns of the record, or * if ReturningStar is set. It uses
+// the same arguments as Delete, and it is only supported by the dialects with
+// RETURNING, see SupportsReturning.
+func (q *QueryBuilder) DeleteWithReturning() (string, error) {
+	return q.deleteReturningAll("DeleteWithReturning")
+}
+
+// DeleteReturningAll returns the query to mark a record as deleted that returns
+// all the columns of the record, it uses the same arguments as Delete. Like
+// InsertReturningAll and UpdateReturningAll, it returns * if ReturningStar is
+// set, and it is only supported by the dialects with RETURNING, see
+// SupportsReturning.
+func (q *QueryBuilder) DeleteReturningAll() (string, error) {
+	return q.deleteReturningAll("DeleteReturningAll")
+}
+
+func (q *QueryBuilder) deleteReturningAll(method string) (string, error) {
+	if err := q.writable(method); err != nil {
+		return "", err
+	}
+	if err := q.keyed(method); err != nil {
+		return "", err
+	}
+	if !q.SupportsReturning() {
+		return "", q.unsupported(method)
+	}
+	return q.finish(q.delete() + " RETURNING " + q.returningAll()), nil
+}
+
+// NamedDeleteWithReturning returns the query to mark a record as deleted using
+// named values that returns all the columns of the record. The deleted_at value
+// is named deleted_at, and it is not used if SoftDeleteBool or DeletedAtNow are
+// set. It is only
+// supported by the dialects with RETURNING, see SupportsReturning.
+func (q *QueryBuilder) NamedDeleteWithReturning() (string, error) {
+	if err := q.writable("NamedDeleteWithReturning"); err != nil {
+		return "", err
+	}
+	if err := q.keyed("NamedDeleteWithReturning"); err != nil {
+		return "", err
+	}
+	if !q.SupportsReturning() {
+		return "", q.unsupported("NamedDeleteWithReturning")
+	}
+	idName := q.idColumn()
+	col := q.deletedColumn()
+	return q.finish(fmt.Sprintf("UPDATE %s SET %s = %s%s RETURNING %s",
+		q.Table, col, q.deletedValue(q.named(col)), q.namedWhere(idName+" = "+q.named(idName)), q.returningAll())), nil
+}
+
+// PurgeBefore returns the query to permanently delete the records marked as
+// deleted before a cutoff time, the first binding parameter:
+//
+//	DELETE FROM users WHERE deleted_at IS NOT NULL AND deleted_at < $1
+//
+// If SoftDeleteBool is set there is no deletion time, and the query deletes
+// all the records marked as deleted without using a cutoff parameter.
+func (q *QueryBuilder) PurgeBefore() string {
+	q.mustWrite("PurgeBefore")
+	col := q.deletedColumn()
+	if q.SoftDeleteBool {
+		return q.finish(fmt.Sprintf("DELETE FROM %s%s", q.Table, q.where(1, false, col+" = "+q.boolLiteral(true))))
+	}
+	return q.finish(fmt.Sprintf("DELETE FROM %s%s", q.Table, q.where(2, false, q.nullCheck(col, true), col+" < "+q.bindFor(col, 1))))
+}
+
+// HardDelete returns the query to delete a row by id.
+func (q *QueryBuilder) HardDelete() string {
+	q.mustWrite("HardDelete")
+	return q.finish(fmt.Sprintf("DELETE FROM %s%s", q.Table, q.where(2, false, q.idColumn()+" = "+q.bindFor(q.idColumn(), 1))))
+}
+
+// Validate checks that the query builder is properly configured. It verifies
+// that the table and columns are set, that the binding and named parameter
+// types and the dialect are valid, that the primary key is one of the columns,
+// that the soft delete column is present if deleted records are filtered out,
+// and that the ReturningOrder columns are unique columns. The returned error is
+// a *ValidationError with all the problems found.
+func (q *QueryBuilder) Validate() error {
+	var errs []error
+	if q.Table == "" {
+		errs = append(errs, errors.New("table name is empty"))
+	}
+	if len(q.Columns) == 0 {
+		errs = append(errs, ErrNoColumns)
+	}
+	switch q.BindType {
+	case 0, DOLLAR, QUESTION:
+	default:
+		errs = append(errs, fmt.Errorf("binding parameter type %d is not valid", q.BindType))
+	}
+	switch q.NamedType {
+	case 0, COLON, AT:
+	default:
+		errs = append(errs, fmt.Errorf("named parameter type %d is not valid", q.NamedType))
+	}
+	switch q.Dialect {
+	case 0, POSTGRES, MYSQL, SQLITE, SQLSERVER:
+	default:
+		errs = append(errs, fmt.Errorf("dialect %d is not valid", q.Dialect))
+	}
+	if q.PrimaryKey != "" && !q.HasColumn(q.PrimaryKey) {
+		errs = append(errs, fmt.Errorf("primary key %q is an %w", q.PrimaryKey, ErrUnknownColumn))
+	}
+	if !q.SelectDeleted && !q.HasColumn(q.deletedColumn()) {
+		errs = append(errs, fmt.Errorf("column %q is required to filter deleted records", q.deletedColumn()))
+	}
+	if err := q.validateReturningOrder(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// validateReturningOrder checks that the ReturningOrder columns are unique
+// columns of the query builder.
+func (q *QueryBuilder) validateReturningOrder() error {
+	for i, name := range q.ReturningOrder {
+		if !q.HasColumn(name) {
+			return fmt.Errorf("returning %w %q in table %s", ErrUnknownColumn, name, q.Table)
+		}
+		if indexOf(q.ReturningOrder[:i], name) >= 0 {
+			return fmt.Errorf("returning column %q is duplicated", name)
+		}
+	}
+	return nil
+}
+
+// sortColumns sorts the columns by name, with the primary key first.
+func sortColumns(columns []string, pk string) {
+	sort.SliceStable(columns, func(i, j int) bool {
+		if columns[i] == pk || columns[j] == pk {
+			return columns[i] == pk && columns[j] != pk
+		}
+		return columns[i] < columns[j]
+	})
+}
+
+// PrependCTE returns the main query prefixed with the common table expression
+// "WITH name AS (cte)". If the main query already starts with a WITH clause the
+// new expression is added as the first one, so several expressions can be
+// chained calling PrependCTE in reverse order:
+//
+//	s := qb.PrependCTE("a", "SELECT ...", qb.PrependCTE("b", "SELECT ...", main))
+//
+// The binding parameters are not renumbered, the parameters of the common table
+// expressions come first, so the main query must start numbering them after the
+// ones in the expressions.
+func PrependCTE(name, cte, main string) string {
+	with := "WITH " + name + " AS (" + cte + ")"
+	switch {
+	case hasPrefixFold(main, "WITH RECURSIVE "):
+		return "WITH RECURSIVE " + name + " AS (" + cte + "), " + main[len("WITH RECURSIVE "):]
+	case hasPrefixFold(main, "WITH "):
+		return with + ", " + main[len("WITH "):]
+	default:
+		return with + " " + main
+	}
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// writable returns an error if the query builder is read-only and the given
+// method cannot be used.
+func (q *QueryBuilder) writable(method string) error {
+	if q.ReadOnly {
+		return fmt.Errorf("%w: %s is not allowed on table %s", ErrReadOnly, method, q.Table)
+	}
+	return nil
+}
+
+// mustWrite panics if the query builder is read-only and the given method
+// cannot be used.
+func (q *QueryBuilder) mustWrite(method string) {
+	if err := q.writable(method); err != nil {
+		panic(err)
+	}
+}
+
+// keyed returns an error if the query builder does not have a primary key,
+// see HasPrimaryKey. It is used by the queries that use the primary key and
+// return an error.
+func (q *QueryBuilder) keyed(method string) error {
+	if !q.HasPrimaryKey() {
+		return fmt.Errorf("%w: %s requires a primary key in table %s", ErrUnknownColumn, method, q.Table)
+	}
+	return nil
+}
+
+// where returns the WHERE clause with the given predicates followed by the
+// tenant predicate, using the binding parameter at position pos, and, if
+// softDelete is true, the predicate that filters deleted records. It returns an
+// empty string if there are no predicates. All the queries must use it to keep
+// the canonical order of the predicates, see TenantColumn.
+func (q *QueryBuilder) where(pos int, softDelete bool, preds ...string) string {
+	if q.TenantColumn == "" && (!softDelete || q.SelectDeleted) && len(preds) == 1 {
+		return " WHERE " + preds[0]
+	}
+	if q.TenantColumn != "" {
+		preds = append(preds, q.TenantColumn+" = "+q.bindFor(q.TenantColumn, pos))
+	}
+	if softDelete && !q.SelectDeleted {
+		preds = append(preds, q.notDeleted())
+	}
+	if len(preds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(preds, " AND ")
+}
+
+// namedWhere returns the WHERE clause with the given predicates followed by
+// the tenant predicate using a named value.
+func (q *QueryBuilder) namedWhere(preds ...string) string {
+	if q.TenantColumn != "" {
+		preds = append(preds, q.TenantColumn+" = "+q.named(q.TenantColumn))
+	}
+	return " WHERE " + strings.Join(preds, " AND ")
+}
+
+// primaryKeys returns the columns of the primary key. Composite primary keys
+// are not supported, so it always returns a single column.
+func (q *QueryBuilder) primaryKeys() []string {
+	return []string{q.idColumn()}
+}
+
+// deletedColumn returns the column used to mark the deleted records.
+func (q *QueryBuilder) deletedColumn() string {
+	if q.SoftDeleteColumn != "" {
+		return q.SoftDeleteColumn
+	}
+	return deletedAtColumn
+}
+
+// notDeleted returns the predicate that filters out the deleted records. The
+// predicate is exactly "deleted_at IS NULL", with the configured column, so it
+// matches the predicate of a partial index on the live records; it is
+// "deleted_at ISNULL" if ShortIsNull is set, and "is_deleted = FALSE" if
+// SoftDeleteBool is set.
+func (q *QueryBuilder) notDeleted() string {
+	if q.SoftDeleteBool {
+		return q.deletedColumn() + " = " + q.boolLiteral(false)
+	}
+	return q.nullCheck(q.deletedColumn(), false)
+}
+
+// nullCheck returns the predicate that checks if the column is NULL, or not
+// NULL if not is true, in the form configured with ShortIsNull.
+func (q *QueryBuilder) nullCheck(col string, not bool) string {
+	if q.ShortIsNull {
+		switch q.dialect() {
+		case POSTGRES, SQLITE:
+			if not {
+				return col + " NOTNULL"
+			}
+			return col + " ISNULL"
+		}
+	}
+	if not {
+		return col + " IS NOT NULL"
+	}
+	return col + " IS NULL"
+}
+
+// boolLiteral returns the boolean literal in the dialect of the query builder,
+// SQL Server does not support TRUE and FALSE and uses 1 and 0.
+func (q *QueryBuilder) boolLiteral(v bool) string {
+	switch {
+	case q.dialect() == SQLSERVER && v:
+		return "1"
+	case q.dialect() == SQLSERVER:
+		return "0"
+	case v:
+		return "TRUE"
+	default:
+		return "FALSE"
+	}
+}
+
+// HasPrimaryKey reports whether the query builder has a primary key, either
+// set in PrimaryKey or an id column. Tables without a primary key, like
+// append-only logs, can use the insert queries and SelectAll, the queries that
+// use the primary key and return an error, like Upsert or UpdateQuery, return
+// an ErrUnknownColumn error on them, and the insert queries do not return the
+// key.
+func (q *QueryBuilder) HasPrimaryKey() bool {
+	return q.PrimaryKey != "" || q.HasColumn(idColumn)
+}
+
+// ColumnGroups returns the columns of the query builder grouped by how they are
+// used in the queries: the primary key columns, the columns that are written
+// by both inserts and updates, and the read-only columns that are only written
+// by inserts, like created_at. The version column, see OptimisticLock, is
+// writable, it is incremented by the updates instead of set to an argument.
+// The returned slices can be safely modified.
+func (q *QueryBuilder) ColumnGroups() (keys, writable, readonly []string) {
+	idName := q.idColumn()
+	for _, name := range q.Columns {
+		switch {
+		case name == idName:
+			keys = append(keys, name)
+		case q.isUpdatable(name, idName), name == q.VersionColumn:
+			writable = append(writable, name)
+		default:
+			readonly = append(readonly, name)
+		}
+	}
+	return
+}
+
+// updatableColumns returns the columns written by the update queries.
+func (q *QueryBuilder) updatableColumns() []string {
+	var columns []string
+	idName := q.idColumn()
+	for _, name := range q.Columns {
+		if q.isUpdatable(name, idName) {
+			columns = append(columns, name)
+		}
+	}
+	return columns
+}
+
+// appendVersion appends the assignment that increments the version column to
+// the given SET assignments if VersionColumn is set. The current version is
+// qualified with the given alias, if any, in the statements that reference
+// other rows, like the upserts.
+func (q *QueryBuilder) appendVersion(set []string, alias string) []string {
+	if q.VersionColumn == "" {
+		return set
+	}
+	return append(set, q.VersionColumn+" = "+qualify(alias, q.VersionColumn)+" + 1")
+}
+
+// isUpdatable returns if the given column is written by the update queries.
+func (q *QueryBuilder) isUpdatable(name, idName string) bool {
+	return name != idName && name != createdAtColumn && name != q.VersionColumn
+}
+
+// HasColumn reports whether the given name is one of the columns of the query
+// builder.
+func (q *QueryBuilder) HasColumn(name string) bool {
+	return indexOf(q.Columns, name) >= 0
+}
+
+// CreateTable returns a CREATE TABLE statement with the table columns. The
+// type of the columns defaults to TEXT and can be configured using the
+// ColumnType option. The statement is just a starting point for a migration,
+// constraints other than the primary key, defaults, and indexes are not
+// included.
+func (q *QueryBuilder) CreateTable() string {
+	return q.finish(q.createTable(""))
+}
+
+// CreateTableOption is the type used to pass options to CreateTableWith.
+type CreateTableOption func(o *createTableOptions)
+
+type createTableOptions struct {
+	modifier string
+}
+
+// TableModifier sets the modifier written between CREATE and TABLE, like
+// UNLOGGED or TEMPORARY, for example, to create the staging tables of an
+// import:
+//
+//	CREATE UNLOGGED TABLE users (id TEXT PRIMARY KEY, name TEXT)
+//
+// UNLOGGED is only supported by PostgreSQL, and TEMPORARY by all the dialects
+// but SQL Server, that uses tables prefixed with # instead.
+func TableModifier(modifier string) CreateTableOption {
+	return func(o *createTableOptions) {
+		o.modifier = strings.ToUpper(modifier)
+	}
+}
+
+// CreateTableWith returns the CREATE TABLE statement of CreateTable with the
+// given options. It returns an error if an option is not supported by the
+// dialect of the query builder.
+func (q *QueryBuilder) CreateTableWith(opts ...CreateTableOption) (string, error) {
+	o := new(createTableOptions)
+	for _, fn := range opts {
+		fn(o)
+	}
+	switch o.modifier {
+	case "":
+	case "UNLOGGED":
+		if q.dialect() != POSTGRES {
+			return "", q.unsupported("TableModifier(UNLOGGED)")
+		}
+	case "TEMPORARY":
+		if q.dialect() == SQLSERVER {
+			return "", q.unsupported("TableModifier(TEMPORARY)")
+		}
+	default:
+		return "", fmt.Errorf("unsupported table modifier %q", o.modifier)
+	}
+	return q.finish(q.createTable(o.modifier)), nil
+}
+
+func (q *QueryBuilder) createTable(modifier string) string {
+	idName := q.idColumn()
+	defs := make([]string, len(q.Columns))
+	for i, name := range q.Columns {
+		typ := q.ColumnTypes[name]
+		if typ == "" {
+			typ = defaultSQLType
+		}
+		defs[i] = name + " " + typ
+		if name == idName {
+			defs[i] += " PRIMARY KEY"
+		}
+	}
+	if modifier != "" {
+		modifier += " "
+	}
+	return fmt.Sprintf("CREATE %sTABLE %s (%s)", modifier, q.Table, q.prettyList(defs))
+}
+
+// valuesClause returns the VALUES clause with the given tuples, surrounded by
+// the whitespace that separates it from the rest of the query. If Pretty is set
+// and there are multiple tuples, each tuple is written in its own line.
+func (q *QueryBuilder) valuesClause(tuples []string) string {
+	if !q.Pretty || len(tuples) < 2 {
+		return " VALUES " + join(tuples) + " "
+	}
+	return "\nVALUES" + q.prettyList(tuples)
+}
+
+// prettyList joins the given elements with commas. If Pretty is set, each
+// element is written in its own indented line.
+func (q *QueryBuilder) prettyList(s []string) string {
+	if !q.Pretty {
+		return join(s)
+	}
+	return "\n  " + strings.Join(s, ",\n  ") + "\n"
+}
+
+func (q *QueryBuilder) idColumn() string {
+	if q.PrimaryKey != "" {
+		return q.PrimaryKey
+	}
+	return idColumn
+}
+
+func (q *QueryBuilder) bind(i int) string {
+	switch q.BindType {
+	case QUESTION:
+		if q.NumberedBinds {
+			return "?" + strconv.Itoa(i)
+		}
+		return "?"
+	default:
+		return "$" + strconv.Itoa(i)
+	}
+}
+
+// bindFor returns the binding parameter at position i for the given column,
+// with the cast set using the Cast option, if any.
+func (q *QueryBuilder) bindFor(name string, i int) string {
+	if typ, ok := q.Casts[name]; ok && q.BindType != QUESTION {
+		return q.bind(i) + "::" + typ
+	}
+	return q.bind(i)
+}
+
+// named returns the named parameter for the given column.
+func (q *QueryBuilder) named(name string) string {
+	if q.NamedType == AT {
+		return "@" + name
+	}
+	return ":" + name
+}
+
+func (q *QueryBuilder) columns() string {
+	return strings.Join(q.Columns, ", ")
+}
+
+// ReturningColumns returns the list of columns qualified with the given
+// alias, like alias.id, alias.name, ... It can be used in RETURNING clauses
+// where the columns are ambiguous, like inserts in common table expressions. If
+// the alias is empty the columns are not qualified.
+func (q *QueryBuilder) ReturningColumns(alias string) string {
+	return q.qualifiedColumns(alias)
+}
+
+// ScanColumns returns the list of columns qualified with the table name and
+// aliased with the given prefix, like users.id AS prefix_id, users.name AS
+// prefix_name, ... It can be used in joins to scan the columns of each table
+// into a different struct. If the prefix is empty the table name is used. The
+// dots in qualified column names are replaced by underscores in the aliases.
+func (q *QueryBuilder) ScanColumns(prefix string) string {
+	if prefix == "" {
+		prefix = q.Table
+	}
+	c := make([]string, len(q.Columns))
+	for i, name := range q.Columns {
+		c[i] = qualify(q.Table, name) + " AS " + prefix + "_" + strings.ReplaceAll(name, ".", "_")
+	}
+	return q.format(join(c))
+}
+
+// ColumnIndex returns a map with the position of each column in the select
+// queries. Combined with sql.Rows.Columns it can be used to build scanners
+// that map the result columns to the fields of a struct.
+func (q *QueryBuilder) ColumnIndex() map[string]int {
+	m := make(map[string]int, len(q.Columns))
+	for i, name := range q.Columns {
+		m[name] = i
+	}
+	return m
+}
+
+func (q *QueryBuilder) qualifiedColumns(alias string) string {
+	if alias == "" {
+		return q.columns()
+	}
+	c := make([]string, len(q.Columns))
+	for i, name := range q.Columns {
+		c[i] = qualify(alias, name)
+	}
+	return join(c)
+}
+
+// unqualified returns an error if any of the given columns is a qualified
+// name, like data.email. The queries that read the columns from another source,
+// like EXCLUDED or the source table of UpdateFrom, cannot qualify them.
+func (q *QueryBuilder) unqualified(method string, names []string) error {
+	for _, name := range names {
+		if strings.Contains(name, ".") {
+			return fmt.Errorf("%s cannot use the qualified column %q in table %s", method, name, q.Table)
+		}
+	}
+	return nil
+}
+
+// qualify returns the column name qualified with the given table or alias.
+// Names that contain a dot, like data.email, are already qualified and are
+// returned unchanged.
+func qualify(alias, name string) string {
+	if alias == "" || strings.Contains(name, ".") {
+		return name
+	}
+	return alias + "." + name
+}
+
+// ValuesTuple returns the VALUES tuple used by Insert, ($1, $2, ...), with the
+// binding parameters starting at position startBind. It can be used to build
+// multi-row inserts or inserts in larger statements.
+func (q *QueryBuilder) ValuesTuple(startBind int) string {
+	return "(" + q.valuesFrom(startBind) + ")"
+}
+
+// PlaceholderMap returns a map with the binding parameter of each column,
+// numbered in the order of the columns starting at startBind, like
+// {"id": "$1", "name": "$2"}. The parameters include the casts set with the
+// Cast option. With QUESTION parameters all the values are ?, unless
+// NumberedBinds is set.
+func (q *QueryBuilder) PlaceholderMap(startBind int) map[string]string {
+	m := make(map[string]string, len(q.Columns))
+	for i, name := range q.Columns {
+		m[name] = q.bindFor(name, startBind+i)
+	}
+	return m
+}
+
+// NamedValuesTuple returns the VALUES tuple used by NamedInsert, (:id, :name,
+// ...).
+func (q *QueryBuilder) NamedValuesTuple() string {
+	return "(" + q.namedValues() + ")"
+}
+
+// values returns the list of binding parameters for all the columns.
+func (q *QueryBuilder) values() string {
+	return q.valuesFrom(1)
+}
+
+// valuesFrom returns the list of binding parameters for all the columns
+// starting at position start.
+func (q *QueryBuilder) valuesFrom(start int) string {
+	return q.valuesOf(q.Columns, start)
+}
+
+// valuesOf returns the list of binding parameters for the given columns
+// starting at position start. Tables with one or two columns, like junction
+// tables, skip the intermediate slice.
+func (q *QueryBuilder) valuesOf(columns []string, start int) string {
+	n := len(columns)
+	if len(q.ValueExprs) > 0 || len(q.Casts) > 0 {
+		pos := start
+		c := make([]string, n)
+		for i, name := range columns {
+			v, ok := q.valueExpr(name, q.bindFor(name, pos))
+			if ok {
+				pos++
+			}
+			c[i] = v
+		}
+		return join(c)
+	}
+	switch n {
+	case 1:
+		return q.bind(start)
+	case 2:
+		return q.bind(start) + ", " + q.bind(start+1)
+	}
+	c := make([]string, n)
+	for i := 0; i < n; i++ {
+		c[i] = q.bind(start + i)
+	}
+	return join(c)
+}
+
+// valueExpr returns the value of the given column in insert queries using the
+// given parameter, and reports whether the parameter is used. Without an
+// expression set with ValueExpr the value is the parameter itself.
+func (q *QueryBuilder) valueExpr(name, param string) (string, bool) {
+	expr, ok := q.ValueExprs[name]
+	if !ok {
+		return param, true
+	}
+	if !strings.Contains(expr, ValuePlaceholder) {
+		return expr, false
+	}
+	return strings.Replace(expr, ValuePlaceholder, param, 1), true
+}
+
+// valueBinds returns the number of binding parameters used by values.
+func (q *QueryBuilder) valueBinds() int {
+	n := len(q.Columns)
+	for _, name := range q.Columns {
+		if expr, ok := q.ValueExprs[name]; ok && !strings.Contains(expr, ValuePlaceholder) {
+			n--
+		}
+	}
+	return n
+}
+
+func (q *QueryBuilder) namedValues() string {
+	n := len(q.Columns)
+	if len(q.ValueExprs) > 0 {
+		c := make([]string, n)
+		for i, name := range q.Columns {
+			c[i], _ = q.valueExpr(name, q.named(name))
+		}
+		return join(c)
+	}
+	switch n {
+	case 1:
+		return q.named(q.Columns[0])
+	case 2:
+		return q.named(q.Columns[0]) + ", " + q.named(q.Columns[1])
+	}
 	c := make([]string, n)
 	for i, s := range q.Columns {
-		c[i] = ":" + s
+		c[i] = q.named(s)
 	}
 	return join(c)
 }
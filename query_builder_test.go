@@ -1,7 +1,10 @@
 package qb
 
 import (
+	"errors"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -47,6 +50,19 @@ type testModelTypePtr struct {
 	Email      string `db:"email"`
 }
 
+type testMixedTags struct {
+	ID    string `dbtable:"users" column:"id,pkey"`
+	Name  string `db:"name" column:"full_name"`
+	Email string `db:"email"`
+	Other string `column:"-" db:"other"`
+}
+
+type testUntaggedKey struct {
+	UserID string `db:"user_id"`
+	Name   string `db:"name"`
+	ID     string `db:"id"`
+}
+
 type badModel struct {
 	ID    string `db:"id,pkey"`
 	Name  string `db:"name,pkey"`
@@ -73,23 +89,26 @@ func TestNew(t *testing.T) {
 		{"ok", args{testTable{}, nil}, &QueryBuilder{
 			Table:         "users",
 			Columns:       []string{"id", "name", "email"},
-			SelectDeleted: false,
+			SelectDeleted: true,
 			PrimaryKey:    "id",
 			BindType:      DOLLAR,
+			columnTags:    []string{"db"},
 		}, false},
 		{"ok with interface", args{testTableInterface(), nil}, &QueryBuilder{
 			Table:         "users",
 			Columns:       []string{"id", "name", "email"},
-			SelectDeleted: false,
+			SelectDeleted: true,
 			PrimaryKey:    "id",
 			BindType:      DOLLAR,
+			columnTags:    []string{"db"},
 		}, false},
 		{"ok with no name", args{testTableNoName{}, nil}, &QueryBuilder{
 			Table:         "test_table_no_name",
 			Columns:       []string{"id", "name", "email"},
-			SelectDeleted: false,
+			SelectDeleted: true,
 			PrimaryKey:    "id",
 			BindType:      DOLLAR,
+			columnTags:    []string{"db"},
 		}, false},
 		{"ok with model", args{testModelType{}, nil}, &QueryBuilder{
 			Table:         "model",
@@ -97,6 +116,7 @@ func TestNew(t *testing.T) {
 			SelectDeleted: false,
 			PrimaryKey:    "id",
 			BindType:      DOLLAR,
+			columnTags:    []string{"db"},
 		}, false},
 		{"ok with model ptr", args{testModelTypePtr{string: &s}, nil}, &QueryBuilder{
 			Table:         "model",
@@ -104,341 +124,3739 @@ func TestNew(t *testing.T) {
 			SelectDeleted: false,
 			PrimaryKey:    "id",
 			BindType:      DOLLAR,
+			columnTags:    []string{"db"},
 		}, false},
 		{"ok with table name", args{&testTable{}, []Option{TableName("mytable")}}, &QueryBuilder{
 			Table:         "mytable",
 			Columns:       []string{"id", "name", "email"},
-			SelectDeleted: false,
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with table prefix", args{&testTable{}, []Option{TablePrefix("staging_")}}, &QueryBuilder{
+			Table:         "staging_users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with table prefix and schema", args{&testTable{}, []Option{TablePrefix("staging_"), TableName("auth.users")}}, &QueryBuilder{
+			Table:         "auth.staging_users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
 			PrimaryKey:    "id",
 			BindType:      DOLLAR,
+			columnTags:    []string{"db"},
 		}, false},
 		{"ok with bind type", args{&testTable{}, []Option{BindType(QUESTION)}}, &QueryBuilder{
 			Table:         "users",
 			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      QUESTION,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with numbered placeholders", args{&testTable{}, []Option{BindType(QUESTION), NumberedPlaceholders(true)}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      QUESTION,
+			NumberedBinds: true,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with column types", args{&testTable{}, []Option{ColumnType("id", "UUID"), ColumnType("", "TEXT"), ColumnType("name", "")}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			ColumnTypes:   map[string]string{"id": "UUID"},
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with value expressions", args{&testTable{}, []Option{ValueExpr("email", "lower({})"), ValueExpr("", "NOW()"), ValueExpr("name", "")}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			ValueExprs:    map[string]string{"email": "lower({})"},
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with casts", args{&testTable{}, []Option{Cast("id", "uuid"), Cast("", "text"), Cast("name", "")}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			Casts:         map[string]string{"id": "uuid"},
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with read-only table", args{&testTable{}, []Option{ReadOnlyTable(true)}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			ReadOnly:      true,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with sorted columns", args{&testTable{}, []Option{SortColumns(true)}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "email", "name"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with sorted columns and primary key", args{&testTable{}, []Option{SortColumns(true), FirstFieldIsPrimaryKey(true), ExcludeColumns("id")}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "name",
+			BindType:      DOLLAR,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with optimistic lock", args{&testTable{}, []Option{OptimisticLock("email")}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			VersionColumn: "email",
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with database defaults", args{&testTable{}, []Option{DatabaseDefaults("id"), DatabaseDefaults("email")}}, &QueryBuilder{
+			Table:            "users",
+			Columns:          []string{"id", "name", "email"},
+			SelectDeleted:    true,
+			PrimaryKey:       "id",
+			BindType:         DOLLAR,
+			DatabaseDefaults: []string{"id", "email"},
+			columnTags:       []string{"db"},
+		}, false},
+		{"ok with returning star", args{&testTable{}, []Option{ReturningStar(true)}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			ReturningStar: true,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with returning order", args{&testTable{}, []Option{ReturningOrder("email", "id")}}, &QueryBuilder{
+			Table:          "users",
+			Columns:        []string{"id", "name", "email"},
+			SelectDeleted:  true,
+			PrimaryKey:     "id",
+			BindType:       DOLLAR,
+			ReturningOrder: []string{"email", "id"},
+			columnTags:     []string{"db"},
+		}, false},
+		{"fail with returning order unknown", args{&testTable{}, []Option{ReturningOrder("email", "created_at")}}, nil, true},
+		{"fail with returning order duplicate", args{&testTable{}, []Option{ReturningOrder("email", "id", "email")}}, nil, true},
+		{"ok with soft delete bool", args{&testTable{}, []Option{SoftDeleteBool("name")}}, &QueryBuilder{
+			Table:            "users",
+			Columns:          []string{"id", "name", "email"},
+			SelectDeleted:    false,
+			PrimaryKey:       "id",
+			BindType:         DOLLAR,
+			SoftDeleteColumn: "name",
+			SoftDeleteBool:   true,
+			columnTags:       []string{"db"},
+		}, false},
+		{"ok with deleted at column", args{&testTable{}, []Option{DeletedAtColumn("email")}}, &QueryBuilder{
+			Table:            "users",
+			Columns:          []string{"id", "name", "email"},
+			SelectDeleted:    false,
+			PrimaryKey:       "id",
+			BindType:         DOLLAR,
+			SoftDeleteColumn: "email",
+			columnTags:       []string{"db"},
+		}, false},
+		{"ok with record queries", args{&testTable{}, []Option{RecordQueries(true)}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			columnTags:    []string{"db"},
+			queryLog:      new(queryLog),
+		}, false},
+		{"ok with short is null", args{&testTable{}, []Option{ShortIsNull(true)}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			ShortIsNull:   true,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with deleted at now", args{&testTable{}, []Option{DeletedAtNow(true)}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			DeletedAtNow:  true,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with pretty", args{&testTable{}, []Option{Pretty(true)}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			Pretty:        true,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with quote reserved", args{&testTable{}, []Option{QuoteReserved([]string{"name"})}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			ReservedWords: []string{"name"},
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with quote reserved default", args{&testTable{}, []Option{Dialect(MYSQL), QuoteReserved(nil)}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			Dialect:       MYSQL,
+			ReservedWords: MySQLReservedWords,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with lowercase", args{&testTable{}, []Option{Lowercase(true)}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			Lowercase:     true,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with tenant column", args{&testTable{}, []Option{TenantColumn("tenant_id")}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			TenantColumn:  "tenant_id",
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with order by primary key", args{&testTable{}, []Option{DefaultOrderByPrimaryKey(true)}}, &QueryBuilder{
+			Table:             "users",
+			Columns:           []string{"id", "name", "email"},
+			SelectDeleted:     true,
+			PrimaryKey:        "id",
+			BindType:          DOLLAR,
+			OrderByPrimaryKey: true,
+			columnTags:        []string{"db"},
+		}, false},
+		{"ok with first field primary key", args{testUntaggedKey{}, []Option{FirstFieldIsPrimaryKey(true)}}, &QueryBuilder{
+			Table:         "test_untagged_key",
+			Columns:       []string{"user_id", "name", "id"},
+			SelectDeleted: true,
+			PrimaryKey:    "user_id",
+			BindType:      DOLLAR,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with first field primary key tagged", args{testTable{}, []Option{ColumnTag("col"), FirstFieldIsPrimaryKey(true)}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"foo_id", "foo_name", "foo_email"},
+			SelectDeleted: true,
+			PrimaryKey:    "foo_id",
+			BindType:      DOLLAR,
+			columnTags:    []string{"col"},
+		}, false},
+		{"ok without first field primary key", args{testUntaggedKey{}, []Option{FirstFieldIsPrimaryKey(false)}}, &QueryBuilder{
+			Table:         "test_untagged_key",
+			Columns:       []string{"user_id", "name", "id"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with named type", args{&testTable{}, []Option{NamedType(AT)}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			NamedType:     AT,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with dialect", args{&testTable{}, []Option{BindType(QUESTION), Dialect(MYSQL)}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      QUESTION,
+			Dialect:       MYSQL,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with options", args{testTable{}, []Option{TableTag("table"), ColumnTag("col"), BindType(QUESTION)}}, &QueryBuilder{
+			Table:         "foo",
+			Columns:       []string{"foo_id", "foo_name", "foo_email"},
+			SelectDeleted: true,
+			PrimaryKey:    "foo_id",
+			BindType:      QUESTION,
+			columnTags:    []string{"col"},
+		}, false},
+		{"ok with deprecated options", args{testTable{}, []Option{TableTag("table"), WithColumnTag("col")}}, &QueryBuilder{
+			Table:         "foo",
+			Columns:       []string{"foo_id", "foo_name", "foo_email"},
+			SelectDeleted: true,
+			PrimaryKey:    "foo_id",
+			BindType:      DOLLAR,
+			columnTags:    []string{"col"},
+		}, false},
+		{"ok with column tags", args{testMixedTags{}, []Option{ColumnTags("column", "db")}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "full_name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			columnTags:    []string{"column", "db"},
+		}, false},
+		{"ok with empty column tags", args{testTable{}, []Option{ColumnTags("", "")}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok with exclude columns", args{testModelType{}, []Option{ExcludeColumns("email", "deleted_at")}}, &QueryBuilder{
+			Table:         "model",
+			Columns:       []string{"id", "created_at", "name"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			columnTags:    []string{"db"},
+		}, false},
+		{"fail", args{"not a struct", nil}, nil, true},
+		{"fail exclude columns", args{testModelType{}, []Option{ExcludeColumns("foo")}}, nil, true},
+		{"fail primary keys", args{badModel{}, nil}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.args.i, tt.args.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("New() = %v, want %v", got, tt.want)
+			}
+
+			defer func() {
+				r := recover()
+				if r != nil != tt.wantErr {
+					t.Errorf("Must() panic = %v, wantErr %v", r, tt.wantErr)
+				}
+			}()
+			gotMust := Must(tt.args.i, tt.args.opts...)
+			if !reflect.DeepEqual(gotMust, tt.want) {
+				t.Errorf("Must() = %v, want %v", gotMust, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFromType(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     reflect.Type
+		opts    []Option
+		want    *QueryBuilder
+		wantErr bool
+	}{
+		{"ok", reflect.TypeOf(testTable{}), nil, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok pointer", reflect.TypeOf(&testModelType{}), []Option{BindType(QUESTION)}, &QueryBuilder{
+			Table:         "model",
+			Columns:       []string{"id", "created_at", "deleted_at", "name", "email"},
 			SelectDeleted: false,
 			PrimaryKey:    "id",
 			BindType:      QUESTION,
+			columnTags:    []string{"db"},
+		}, false},
+		{"ok pointer to pointer", reflect.TypeOf(new(*testTableNoName)), nil, &QueryBuilder{
+			Table:         "test_table_no_name",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			columnTags:    []string{"db"},
+		}, false},
+		{"fail nil", nil, nil, nil, true},
+		{"fail not struct", reflect.TypeOf("string"), nil, nil, true},
+		{"fail primary keys", reflect.TypeOf(badModel{}), nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewFromType(tt.typ, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewFromType() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewFromType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDialects(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(any, ...Option) (*QueryBuilder, error)
+		opts []Option
+		want *QueryBuilder
+	}{
+		{"postgres", NewPostgres, nil, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+			Dialect:       POSTGRES,
+			columnTags:    []string{"db"},
+		}},
+		{"mysql", NewMySQL, nil, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      QUESTION,
+			Dialect:       MYSQL,
+			columnTags:    []string{"db"},
+		}},
+		{"sqlite", NewSQLite, []Option{TableName("people")}, &QueryBuilder{
+			Table:         "people",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      QUESTION,
+			Dialect:       SQLITE,
+			columnTags:    []string{"db"},
+		}},
+		{"sqlite numbered", NewSQLite, []Option{NumberedPlaceholders(true)}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      QUESTION,
+			NumberedBinds: true,
+			Dialect:       SQLITE,
+			columnTags:    []string{"db"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn(testTable{}, tt.opts...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("New() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewQueryBuilder(t *testing.T) {
+	type args struct {
+		table   string
+		columns []string
+	}
+	tests := []struct {
+		name string
+		args args
+		want *QueryBuilder
+	}{
+		{"ok", args{"users", []string{"id", "name", "email"}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "email"},
+			SelectDeleted: true,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+		}},
+		{"ok with deleted_at", args{"users", []string{"id", "name", "deleted_at"}}, &QueryBuilder{
+			Table:         "users",
+			Columns:       []string{"id", "name", "deleted_at"},
+			SelectDeleted: false,
+			PrimaryKey:    "id",
+			BindType:      DOLLAR,
+		}},
+		{"ok without id", args{"logs", []string{"message", "created_at"}}, &QueryBuilder{
+			Table:         "logs",
+			Columns:       []string{"message", "created_at"},
+			SelectDeleted: true,
+			BindType:      DOLLAR,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewQueryBuilder(tt.args.table, tt.args.columns); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewQueryBuilder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectOnto(t *testing.T) {
+	base := &QueryBuilder{
+		Table:             "users",
+		Columns:           []string{"id", "org_id", "name", "email", "password", "deleted_at"},
+		PrimaryKey:        "id",
+		BindType:          QUESTION,
+		TenantColumn:      "org_id",
+		OrderByPrimaryKey: true,
+	}
+	tests := []struct {
+		name    string
+		cols    []string
+		want    *QueryBuilder
+		wantErr bool
+	}{
+		{"ok", []string{"id", "org_id", "email", "deleted_at"}, &QueryBuilder{
+			Table:             "users",
+			Columns:           []string{"id", "org_id", "email", "deleted_at"},
+			PrimaryKey:        "id",
+			BindType:          QUESTION,
+			TenantColumn:      "org_id",
+			OrderByPrimaryKey: true,
+		}, false},
+		{"fail unknown column", []string{"id", "org_id", "deleted_at", "created_at"}, nil, true},
+		{"fail no columns", nil, nil, true},
+		{"fail primary key", []string{"org_id", "email", "deleted_at"}, nil, true},
+		{"fail tenant", []string{"id", "email", "deleted_at"}, nil, true},
+		{"fail deleted at", []string{"id", "org_id", "email"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProjectOnto(base, tt.cols...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ProjectOnto() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ProjectOnto() = %v, want %v", got, tt.want)
+			}
+			if got != nil {
+				if err := got.Validate(); err != nil {
+					t.Errorf("ProjectOnto() Validate() error = %v", err)
+				}
+			}
+		})
+	}
+	if want := []string{"id", "org_id", "name", "email", "password", "deleted_at"}; !reflect.DeepEqual(base.Columns, want) {
+		t.Errorf("ProjectOnto() modified base columns = %v, want %v", base.Columns, want)
+	}
+	if _, err := ProjectOnto(base, "id", "created_at"); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("ProjectOnto() error = %v, want ErrUnknownColumn", err)
+	}
+	if _, err := ProjectOnto(base); !errors.Is(err, ErrNoColumns) {
+		t.Errorf("ProjectOnto() error = %v, want ErrNoColumns", err)
+	}
+	q, _ := ProjectOnto(base, "id", "org_id", "email", "deleted_at")
+	if got, want := q.Select(), "SELECT id, org_id, email, deleted_at FROM users WHERE id = ? AND org_id = ? AND deleted_at IS NULL"; got != want {
+		t.Errorf("QueryBuilder.Select() = %v, want %v", got, want)
+	}
+	// Records of tables without soft delete can be projected without it.
+	logs := &QueryBuilder{Table: "logs", Columns: []string{"id", "message", "level"}, SelectDeleted: true}
+	if _, err := ProjectOnto(logs, "id", "message"); err != nil {
+		t.Errorf("ProjectOnto() error = %v", err)
+	}
+}
+
+func TestQueryBuilder_PrimaryKeyBindPositions(t *testing.T) {
+	type fields struct {
+		Columns        []string
+		SoftDeleteBool bool
+		VersionColumn  string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   map[string]int
+	}{
+		{"ok", fields{[]string{"id", "name", "email", "created_at", "deleted_at"}, false, ""}, map[string]int{
+			"Select": 1, "Update": 4, "Delete": 2, "Restore": 1, "HardDelete": 1,
+		}},
+		{"ok version", fields{[]string{"id", "name", "version", "deleted_at"}, false, "version"}, map[string]int{
+			"Select": 1, "Update": 3, "Delete": 2, "Restore": 1, "HardDelete": 1,
+		}},
+		{"ok soft delete bool", fields{[]string{"id", "name", "is_deleted"}, true, ""}, map[string]int{
+			"Select": 1, "Update": 3, "Delete": 1, "Restore": 1, "HardDelete": 1,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:          "users",
+				Columns:        tt.fields.Columns,
+				BindType:       DOLLAR,
+				SoftDeleteBool: tt.fields.SoftDeleteBool,
+				VersionColumn:  tt.fields.VersionColumn,
+			}
+			if tt.fields.SoftDeleteBool {
+				q.SoftDeleteColumn = "is_deleted"
+			}
+			got := q.PrimaryKeyBindPositions()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryBuilder.PrimaryKeyBindPositions() = %v, want %v", got, tt.want)
+			}
+			// The positions must match the generated queries.
+			queries := map[string]string{
+				"Select": q.Select(), "Update": q.Update(), "Delete": q.Delete(),
+				"Restore": q.Restore(), "HardDelete": q.HardDelete(),
+			}
+			for name, s := range queries {
+				if want := "id = $" + strconv.Itoa(got[name]); !strings.Contains(s, want) {
+					t.Errorf("QueryBuilder.%s() = %v, want %v", name, s, want)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_WithTable(t *testing.T) {
+	q := &QueryBuilder{
+		Table:         "events",
+		Columns:       []string{"id", "name", "created_at"},
+		PrimaryKey:    "id",
+		BindType:      DOLLAR,
+		ColumnTypes:   map[string]string{"id": "UUID"},
+		SelectDeleted: true,
+	}
+	got, err := q.WithTable("events_2024_01")
+	if err != nil {
+		t.Fatalf("QueryBuilder.WithTable() error = %v", err)
+	}
+	want := &QueryBuilder{
+		Table:         "events_2024_01",
+		Columns:       []string{"id", "name", "created_at"},
+		PrimaryKey:    "id",
+		BindType:      DOLLAR,
+		ColumnTypes:   map[string]string{"id": "UUID"},
+		SelectDeleted: true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("QueryBuilder.WithTable() = %v, want %v", got, want)
+	}
+	if got, want := got.Select(), "SELECT id, name, created_at FROM events_2024_01 WHERE id = $1"; got != want {
+		t.Errorf("QueryBuilder.Select() = %v, want %v", got, want)
+	}
+
+	// The copy does not share the columns with the original.
+	got.Columns[1] = "title"
+	got.ColumnTypes["id"] = "TEXT"
+	if q.Table != "events" || q.Columns[1] != "name" || q.ColumnTypes["id"] != "UUID" {
+		t.Errorf("QueryBuilder.WithTable() modified the original query builder: %v", q)
+	}
+
+	if _, err := q.WithTable(""); err == nil {
+		t.Error("QueryBuilder.WithTable() error = nil, want error")
+	}
+}
+
+func TestQueryBuilder_Queries(t *testing.T) {
+	type fields struct {
+		Table         string
+		Columns       []string
+		SelectDeleted bool
+		PrimaryKey    string
+		BindType      BindParam
+		NumberedBinds bool
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+		want1  string
+		want2  string
+		want3  string
+	}{
+		{"selectDeleted", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, true, "", 0, false},
+			"SELECT id, name, email, created_at, deleted_at FROM users WHERE id = $1",
+			"INSERT INTO users (id, name, email, created_at, deleted_at) VALUES ($1, $2, $3, $4, $5)",
+			"UPDATE users SET name = $1, email = $2, deleted_at = $3 WHERE id = $4",
+			"UPDATE users SET deleted_at = $1 WHERE id = $2"},
+		{"selectWithCustomId", fields{"users", []string{"oid", "name", "email", "created_at", "deleted_at"}, true, "oid", DOLLAR, false},
+			"SELECT oid, name, email, created_at, deleted_at FROM users WHERE oid = $1",
+			"INSERT INTO users (oid, name, email, created_at, deleted_at) VALUES ($1, $2, $3, $4, $5)",
+			"UPDATE users SET name = $1, email = $2, deleted_at = $3 WHERE oid = $4",
+			"UPDATE users SET deleted_at = $1 WHERE oid = $2"},
+		{"noSelectDeleted", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, false, "id", QUESTION, false},
+			"SELECT id, name, email, created_at, deleted_at FROM users WHERE id = ? AND deleted_at IS NULL",
+			"INSERT INTO users (id, name, email, created_at, deleted_at) VALUES (?, ?, ?, ?, ?)",
+			"UPDATE users SET name = ?, email = ?, deleted_at = ? WHERE id = ?",
+			"UPDATE users SET deleted_at = ? WHERE id = ?"},
+		{"numberedQuestion", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, false, "id", QUESTION, true},
+			"SELECT id, name, email, created_at, deleted_at FROM users WHERE id = ?1 AND deleted_at IS NULL",
+			"INSERT INTO users (id, name, email, created_at, deleted_at) VALUES (?1, ?2, ?3, ?4, ?5)",
+			"UPDATE users SET name = ?1, email = ?2, deleted_at = ?3 WHERE id = ?4",
+			"UPDATE users SET deleted_at = ?1 WHERE id = ?2"},
+		{"numberedDollar", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, false, "id", DOLLAR, true},
+			"SELECT id, name, email, created_at, deleted_at FROM users WHERE id = $1 AND deleted_at IS NULL",
+			"INSERT INTO users (id, name, email, created_at, deleted_at) VALUES ($1, $2, $3, $4, $5)",
+			"UPDATE users SET name = $1, email = $2, deleted_at = $3 WHERE id = $4",
+			"UPDATE users SET deleted_at = $1 WHERE id = $2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         tt.fields.Table,
+				Columns:       tt.fields.Columns,
+				SelectDeleted: tt.fields.SelectDeleted,
+				PrimaryKey:    tt.fields.PrimaryKey,
+				BindType:      tt.fields.BindType,
+				NumberedBinds: tt.fields.NumberedBinds,
+			}
+			got, got1, got2, got3 := q.Queries()
+			if got != tt.want {
+				t.Errorf("QueryBuilder.Queries() got = %v, want %v", got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("QueryBuilder.Queries() got1 = %v, want %v", got1, tt.want1)
+			}
+			if got2 != tt.want2 {
+				t.Errorf("QueryBuilder.Queries() got2 = %v, want %v", got2, tt.want2)
+			}
+			if got3 != tt.want3 {
+				t.Errorf("QueryBuilder.Queries() got3 = %v, want %v", got3, tt.want3)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_SelectBy(t *testing.T) {
+	type fields struct {
+		Table         string
+		Columns       []string
+		SelectDeleted bool
+	}
+	type args struct {
+		name       string
+		extraNames []string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   string
+	}{
+		{"selectDeleted", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, true}, args{"email", nil}, "SELECT id, name, email, created_at, deleted_at FROM users WHERE email = $1"},
+		{"noSelectDeleted", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, false}, args{"email", nil}, "SELECT id, name, email, created_at, deleted_at FROM users WHERE email = $1 AND deleted_at IS NULL"},
+		{"extra names", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, false}, args{"name", []string{"email"}}, "SELECT id, name, email, created_at, deleted_at FROM users WHERE name = $1 AND email = $2 AND deleted_at IS NULL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         tt.fields.Table,
+				Columns:       tt.fields.Columns,
+				SelectDeleted: tt.fields.SelectDeleted,
+			}
+			if got := q.SelectBy(tt.args.name, tt.args.extraNames...); got != tt.want {
+				t.Errorf("QueryBuilder.SelectBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_SelectByLower(t *testing.T) {
+	type fields struct {
+		Table         string
+		Columns       []string
+		SelectDeleted bool
+		BindType      BindParam
+		TenantColumn  string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{"ok", fields{"users", []string{"id", "email", "deleted_at"}, false, DOLLAR, ""}, "SELECT id, email, deleted_at FROM users WHERE LOWER(email) = LOWER($1) AND deleted_at IS NULL"},
+		{"selectDeleted", fields{"users", []string{"id", "email"}, true, QUESTION, ""}, "SELECT id, email FROM users WHERE LOWER(email) = LOWER(?)"},
+		{"tenant", fields{"users", []string{"id", "email", "deleted_at"}, false, DOLLAR, "tenant_id"}, "SELECT id, email, deleted_at FROM users WHERE LOWER(email) = LOWER($1) AND tenant_id = $2 AND deleted_at IS NULL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         tt.fields.Table,
+				Columns:       tt.fields.Columns,
+				SelectDeleted: tt.fields.SelectDeleted,
+				BindType:      tt.fields.BindType,
+				TenantColumn:  tt.fields.TenantColumn,
+			}
+			if got := q.SelectByLower("email"); got != tt.want {
+				t.Errorf("QueryBuilder.SelectByLower() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_SelectByAny(t *testing.T) {
+	type fields struct {
+		Table         string
+		Columns       []string
+		SelectDeleted bool
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		names  []string
+		want   string
+	}{
+		{"one", fields{"users", []string{"id", "email", "deleted_at"}, false}, []string{"email"}, "SELECT id, email, deleted_at FROM users WHERE (email = $1) AND deleted_at IS NULL"},
+		{"many", fields{"users", []string{"id", "email", "username", "deleted_at"}, false}, []string{"email", "username"}, "SELECT id, email, username, deleted_at FROM users WHERE (email = $1 OR username = $2) AND deleted_at IS NULL"},
+		{"selectDeleted", fields{"users", []string{"id", "email", "username"}, true}, []string{"email", "username"}, "SELECT id, email, username FROM users WHERE (email = $1 OR username = $2)"},
+		{"none", fields{"users", []string{"id", "email", "deleted_at"}, false}, nil, "SELECT id, email, deleted_at FROM users WHERE deleted_at IS NULL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         tt.fields.Table,
+				Columns:       tt.fields.Columns,
+				SelectDeleted: tt.fields.SelectDeleted,
+			}
+			if got := q.SelectByAny(tt.names...); got != tt.want {
+				t.Errorf("QueryBuilder.SelectByAny() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_SelectIn(t *testing.T) {
+	type fields struct {
+		Columns      []string
+		BindType     BindParam
+		TenantColumn string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		n      int
+		want   string
+	}{
+		{"one", fields{[]string{"id", "email", "deleted_at"}, DOLLAR, ""}, 1, "SELECT id, email, deleted_at FROM users WHERE id IN ($1) AND deleted_at IS NULL"},
+		{"many", fields{[]string{"id", "email"}, DOLLAR, "tenant_id"}, 3, "SELECT id, email FROM users WHERE id IN ($1, $2, $3) AND tenant_id = $4"},
+		{"question", fields{[]string{"id", "email", "deleted_at"}, QUESTION, ""}, 2, "SELECT id, email, deleted_at FROM users WHERE id IN (?, ?) AND deleted_at IS NULL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewQueryBuilder("users", tt.fields.Columns)
+			q.BindType = tt.fields.BindType
+			q.TenantColumn = tt.fields.TenantColumn
+			if got := q.SelectIn("id", tt.n); got != tt.want {
+				t.Errorf("QueryBuilder.SelectIn() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_SelectInChunked(t *testing.T) {
+	q := NewQueryBuilder("users", []string{"id", "email"})
+	tests := []struct {
+		name      string
+		total     int
+		chunkSize int
+		want      []string
+	}{
+		{"exact", 4, 2, []string{
+			"SELECT id, email FROM users WHERE id IN ($1, $2)",
+			"SELECT id, email FROM users WHERE id IN ($1, $2)",
+		}},
+		{"last smaller", 5, 2, []string{
+			"SELECT id, email FROM users WHERE id IN ($1, $2)",
+			"SELECT id, email FROM users WHERE id IN ($1, $2)",
+			"SELECT id, email FROM users WHERE id IN ($1)",
+		}},
+		{"single chunk", 3, 10, []string{"SELECT id, email FROM users WHERE id IN ($1, $2, $3)"}},
+		{"no chunk size", 2, 0, []string{"SELECT id, email FROM users WHERE id IN ($1, $2)"}},
+		{"no values", 0, 2, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := q.SelectInChunked("id", tt.total, tt.chunkSize); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryBuilder.SelectInChunked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_SelectAll(t *testing.T) {
+	type fields struct {
+		Table             string
+		Columns           []string
+		SelectDeleted     bool
+		PrimaryKey        string
+		OrderByPrimaryKey bool
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{"all", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, true, "", false}, "SELECT id, name, email, created_at, deleted_at FROM users"},
+		{"non deleted", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, false, "", false}, "SELECT id, name, email, created_at, deleted_at FROM users WHERE deleted_at IS NULL"},
+		{"order by id", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, true, "", true}, "SELECT id, name, email, created_at, deleted_at FROM users ORDER BY id"},
+		{"order by primary key", fields{"users", []string{"oid", "name", "email", "created_at", "deleted_at"}, false, "oid", true}, "SELECT oid, name, email, created_at, deleted_at FROM users WHERE deleted_at IS NULL ORDER BY oid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:             tt.fields.Table,
+				Columns:           tt.fields.Columns,
+				SelectDeleted:     tt.fields.SelectDeleted,
+				PrimaryKey:        tt.fields.PrimaryKey,
+				OrderByPrimaryKey: tt.fields.OrderByPrimaryKey,
+			}
+			if got := q.SelectAll(); got != tt.want {
+				t.Errorf("QueryBuilder.SelectAll() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_SelectColumnsOrdered(t *testing.T) {
+	type fields struct {
+		SelectDeleted     bool
+		TenantColumn      string
+		OrderByPrimaryKey bool
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		order   []string
+		want    string
+		wantErr bool
+	}{
+		{"ok", fields{false, "", false}, []string{"email", "id", "name"}, "SELECT email, id, name FROM users WHERE deleted_at IS NULL", false},
+		{"ok subset", fields{true, "", false}, []string{"name"}, "SELECT name FROM users", false},
+		{"ok tenant", fields{false, "org_id", true}, []string{"created_at", "id"}, "SELECT created_at, id FROM users WHERE org_id = $1 AND deleted_at IS NULL ORDER BY id", false},
+		{"fail empty", fields{false, "", false}, nil, "", true},
+		{"fail column", fields{false, "", false}, []string{"email", "phone"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:             "users",
+				Columns:           []string{"id", "name", "email", "created_at", "deleted_at"},
+				SelectDeleted:     tt.fields.SelectDeleted,
+				TenantColumn:      tt.fields.TenantColumn,
+				OrderByPrimaryKey: tt.fields.OrderByPrimaryKey,
+			}
+			got, err := q.SelectColumnsOrdered(tt.order)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.SelectColumnsOrdered() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.SelectColumnsOrdered() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_InsertWithReturning(t *testing.T) {
+	type fields struct {
+		Table         string
+		Columns       []string
+		SelectDeleted bool
+		PrimaryKey    string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{"ok", fields{"users", []string{"id", "name", "email"}, false, ""}, "INSERT INTO users (name, email) VALUES ($1, $2) RETURNING id"},
+		{"ok no id", fields{"users", []string{"name", "email"}, false, "id"}, "INSERT INTO users (name, email) VALUES ($1, $2) RETURNING id"},
+		{"ok no primary key", fields{"logs", []string{"message", "created_at"}, false, ""}, "INSERT INTO logs (message, created_at) VALUES ($1, $2)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         tt.fields.Table,
+				Columns:       tt.fields.Columns,
+				SelectDeleted: tt.fields.SelectDeleted,
+				PrimaryKey:    tt.fields.PrimaryKey,
+			}
+			if got := q.InsertWithReturning(); got != tt.want {
+				t.Errorf("QueryBuilder.InsertWithReturning() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_NamedInsert(t *testing.T) {
+	type fields struct {
+		Table         string
+		Columns       []string
+		SelectDeleted bool
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{"ok", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, false}, "INSERT INTO users (id, name, email, created_at, deleted_at) VALUES (:id, :name, :email, :created_at, :deleted_at)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         tt.fields.Table,
+				Columns:       tt.fields.Columns,
+				SelectDeleted: tt.fields.SelectDeleted,
+			}
+			if got := q.NamedInsert(); got != tt.want {
+				t.Errorf("QueryBuilder.NamedInsert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_NamedInsertWithReturning(t *testing.T) {
+	type fields struct {
+		Table         string
+		Columns       []string
+		SelectDeleted bool
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{"ok", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, false}, "INSERT INTO users (name, email, created_at, deleted_at) VALUES (:name, :email, :created_at, :deleted_at) RETURNING id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         tt.fields.Table,
+				Columns:       tt.fields.Columns,
+				SelectDeleted: tt.fields.SelectDeleted,
+			}
+			if got := q.NamedInsertWithReturning(); got != tt.want {
+				t.Errorf("QueryBuilder.NamedInsertWithReturning() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_NamedUpdate(t *testing.T) {
+	type fields struct {
+		Table         string
+		Columns       []string
+		SelectDeleted bool
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{"ok", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, false}, "UPDATE users SET name = :name, email = :email, deleted_at = :deleted_at WHERE id = :id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         tt.fields.Table,
+				Columns:       tt.fields.Columns,
+				SelectDeleted: tt.fields.SelectDeleted,
+			}
+			if got := q.NamedUpdate(); got != tt.want {
+				t.Errorf("QueryBuilder.NamedUpdate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_HardDelete(t *testing.T) {
+	type fields struct {
+		Table         string
+		Columns       []string
+		SelectDeleted bool
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{"ok", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, false}, "DELETE FROM users WHERE id = $1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         tt.fields.Table,
+				Columns:       tt.fields.Columns,
+				SelectDeleted: tt.fields.SelectDeleted,
+			}
+			if got := q.HardDelete(); got != tt.want {
+				t.Errorf("QueryBuilder.HardDelete() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_Validate(t *testing.T) {
+	type fields struct {
+		Table         string
+		Columns       []string
+		SelectDeleted bool
+		PrimaryKey    string
+		BindType      BindParam
+		Dialect       SQLDialect
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		wantErr string
+	}{
+		{"ok", fields{"users", []string{"id", "name", "deleted_at"}, false, "id", DOLLAR, 0}, ""},
+		{"ok select deleted", fields{"users", []string{"id", "name"}, true, "id", QUESTION, MYSQL}, ""},
+		{"ok default primary key", fields{"users", []string{"id", "name"}, true, "", 0, 0}, ""},
+		{"ok no primary key", fields{"logs", []string{"message", "created_at"}, true, "", 0, 0}, ""},
+		{"fail table", fields{"", []string{"id", "name"}, true, "id", DOLLAR, 0}, "invalid query builder: table name is empty"},
+		{"fail columns", fields{"users", nil, true, "", DOLLAR, 0}, "invalid query builder: columns are empty"},
+		{"fail bind type", fields{"users", []string{"id"}, true, "id", 3, 0}, "invalid query builder: binding parameter type 3 is not valid"},
+		{"fail dialect", fields{"users", []string{"id"}, true, "id", DOLLAR, 10}, "invalid query builder: dialect 10 is not valid"},
+		{"fail primary key", fields{"users", []string{"id"}, true, "oid", DOLLAR, 0}, `invalid query builder: primary key "oid" is an unknown column`},
+		{"fail deleted_at", fields{"users", []string{"id"}, false, "id", DOLLAR, 0}, `invalid query builder: column "deleted_at" is required to filter deleted records`},
+		{"fail multiple", fields{"", nil, false, "id", DOLLAR, 0}, `invalid query builder: table name is empty; columns are empty; primary key "id" is an unknown column; column "deleted_at" is required to filter deleted records`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         tt.fields.Table,
+				Columns:       tt.fields.Columns,
+				SelectDeleted: tt.fields.SelectDeleted,
+				PrimaryKey:    tt.fields.PrimaryKey,
+				BindType:      tt.fields.BindType,
+				Dialect:       tt.fields.Dialect,
+			}
+			err := q.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("QueryBuilder.Validate() error = %v, want nil", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Errorf("QueryBuilder.Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_UpsertPortable(t *testing.T) {
+	type fields struct {
+		Table      string
+		Columns    []string
+		PrimaryKey string
+		BindType   BindParam
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+		want1  string
+	}{
+		{"ok", fields{"users", []string{"id", "name", "email", "created_at"}, "id", DOLLAR},
+			"UPDATE users SET name = $1, email = $2 WHERE id = $3",
+			"INSERT INTO users (id, name, email, created_at) SELECT $1, $2, $3, $4 WHERE NOT EXISTS (SELECT 1 FROM users WHERE id = $5)"},
+		{"ok question", fields{"users", []string{"oid", "name", "email"}, "oid", QUESTION},
+			"UPDATE users SET name = ?, email = ? WHERE oid = ?",
+			"INSERT INTO users (oid, name, email) SELECT ?, ?, ? WHERE NOT EXISTS (SELECT 1 FROM users WHERE oid = ?)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:      tt.fields.Table,
+				Columns:    tt.fields.Columns,
+				PrimaryKey: tt.fields.PrimaryKey,
+				BindType:   tt.fields.BindType,
+			}
+			got, got1 := q.UpsertPortable()
+			if got != tt.want {
+				t.Errorf("QueryBuilder.UpsertPortable() got = %v, want %v", got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("QueryBuilder.UpsertPortable() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_CreateTable(t *testing.T) {
+	type fields struct {
+		Table       string
+		Columns     []string
+		PrimaryKey  string
+		ColumnTypes map[string]string
+		Pretty      bool
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{"ok", fields{"users", []string{"id", "name", "email"}, "", nil, false}, "CREATE TABLE users (id TEXT PRIMARY KEY, name TEXT, email TEXT)"},
+		{"ok with types", fields{"users", []string{"oid", "name", "created_at"}, "oid", map[string]string{"oid": "BIGSERIAL", "created_at": "TIMESTAMPTZ NOT NULL"}, false},
+			"CREATE TABLE users (oid BIGSERIAL PRIMARY KEY, name TEXT, created_at TIMESTAMPTZ NOT NULL)"},
+		{"ok no primary key", fields{"logs", []string{"message", "created_at"}, "", nil, false}, "CREATE TABLE logs (message TEXT, created_at TEXT)"},
+		{"ok pretty", fields{"users", []string{"id", "name", "email"}, "", nil, true}, "CREATE TABLE users (\n  id TEXT PRIMARY KEY,\n  name TEXT,\n  email TEXT\n)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:       tt.fields.Table,
+				Columns:     tt.fields.Columns,
+				PrimaryKey:  tt.fields.PrimaryKey,
+				ColumnTypes: tt.fields.ColumnTypes,
+				Pretty:      tt.fields.Pretty,
+			}
+			if got := q.CreateTable(); got != tt.want {
+				t.Errorf("QueryBuilder.CreateTable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_CreateTableWith(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect SQLDialect
+		opts    []CreateTableOption
+		want    string
+		wantErr bool
+	}{
+		{"ok", POSTGRES, nil, "CREATE TABLE users (id TEXT PRIMARY KEY, name TEXT)", false},
+		{"ok unlogged", POSTGRES, []CreateTableOption{TableModifier("UNLOGGED")}, "CREATE UNLOGGED TABLE users (id TEXT PRIMARY KEY, name TEXT)", false},
+		{"ok temporary", POSTGRES, []CreateTableOption{TableModifier("temporary")}, "CREATE TEMPORARY TABLE users (id TEXT PRIMARY KEY, name TEXT)", false},
+		{"ok temporary mysql", MYSQL, []CreateTableOption{TableModifier("TEMPORARY")}, "CREATE TEMPORARY TABLE users (id TEXT PRIMARY KEY, name TEXT)", false},
+		{"ok temporary sqlite", SQLITE, []CreateTableOption{TableModifier("TEMPORARY")}, "CREATE TEMPORARY TABLE users (id TEXT PRIMARY KEY, name TEXT)", false},
+		{"fail unlogged mysql", MYSQL, []CreateTableOption{TableModifier("UNLOGGED")}, "", true},
+		{"fail temporary sqlserver", SQLSERVER, []CreateTableOption{TableModifier("TEMPORARY")}, "", true},
+		{"fail unknown", POSTGRES, []CreateTableOption{TableModifier("GLOBAL")}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:   "users",
+				Columns: []string{"id", "name"},
+				Dialect: tt.dialect,
+			}
+			got, err := q.CreateTableWith(tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.CreateTableWith() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.CreateTableWith() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_TenantColumn(t *testing.T) {
+	columns := []string{"id", "tenant_id", "name", "email", "created_at", "deleted_at"}
+	tests := []struct {
+		name     string
+		bindType BindParam
+		fn       func(q *QueryBuilder) string
+		want     string
+	}{
+		{"Select", DOLLAR, (*QueryBuilder).Select, "SELECT id, tenant_id, name, email, created_at, deleted_at FROM users WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL"},
+		{"SelectBy", DOLLAR, func(q *QueryBuilder) string { return q.SelectBy("name", "email") },
+			"SELECT id, tenant_id, name, email, created_at, deleted_at FROM users WHERE name = $1 AND email = $2 AND tenant_id = $3 AND deleted_at IS NULL"},
+		{"SelectByAny", DOLLAR, func(q *QueryBuilder) string { return q.SelectByAny("name", "email") },
+			"SELECT id, tenant_id, name, email, created_at, deleted_at FROM users WHERE (name = $1 OR email = $2) AND tenant_id = $3 AND deleted_at IS NULL"},
+		{"SelectAll", DOLLAR, (*QueryBuilder).SelectAll, "SELECT id, tenant_id, name, email, created_at, deleted_at FROM users WHERE tenant_id = $1 AND deleted_at IS NULL"},
+		{"SelectWhere", DOLLAR, func(q *QueryBuilder) string { s, _ := q.SelectWhere(Eq("name", "jane")); return s },
+			"SELECT id, tenant_id, name, email, created_at, deleted_at FROM users WHERE name = $1 AND tenant_id = $2"},
+		{"Update", DOLLAR, (*QueryBuilder).Update, "UPDATE users SET tenant_id = $1, name = $2, email = $3, deleted_at = $4 WHERE id = $5 AND tenant_id = $6"},
+		{"NamedUpdate", DOLLAR, (*QueryBuilder).NamedUpdate,
+			"UPDATE users SET tenant_id = :tenant_id, name = :name, email = :email, deleted_at = :deleted_at WHERE id = :id AND tenant_id = :tenant_id"},
+		{"Delete", DOLLAR, (*QueryBuilder).Delete, "UPDATE users SET deleted_at = $1 WHERE id = $2 AND tenant_id = $3"},
+		{"HardDelete", DOLLAR, (*QueryBuilder).HardDelete, "DELETE FROM users WHERE id = $1 AND tenant_id = $2"},
+		{"UpsertPortable", DOLLAR, func(q *QueryBuilder) string { _, s := q.UpsertPortable(); return s },
+			"INSERT INTO users (id, tenant_id, name, email, created_at, deleted_at) SELECT $1, $2, $3, $4, $5, $6 WHERE NOT EXISTS (SELECT 1 FROM users WHERE id = $7 AND tenant_id = $8)"},
+		{"Insert", DOLLAR, (*QueryBuilder).Insert, "INSERT INTO users (id, tenant_id, name, email, created_at, deleted_at) VALUES ($1, $2, $3, $4, $5, $6)"},
+		{"Select question", QUESTION, (*QueryBuilder).Select, "SELECT id, tenant_id, name, email, created_at, deleted_at FROM users WHERE id = ? AND tenant_id = ? AND deleted_at IS NULL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewQueryBuilder("users", columns)
+			q.TenantColumn = "tenant_id"
+			q.BindType = tt.bindType
+			if got := tt.fn(q); got != tt.want {
+				t.Errorf("QueryBuilder.%s() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_DeleteIfNotDeleted(t *testing.T) {
+	type fields struct {
+		SelectDeleted bool
+		BindType      BindParam
+		TenantColumn  string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{"ok", fields{false, DOLLAR, ""}, "UPDATE users SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL"},
+		{"ok select deleted", fields{true, QUESTION, ""}, "UPDATE users SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL"},
+		{"ok tenant", fields{false, DOLLAR, "tenant_id"}, "UPDATE users SET deleted_at = $1 WHERE id = $2 AND tenant_id = $3 AND deleted_at IS NULL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         "users",
+				Columns:       []string{"id", "name", "deleted_at"},
+				SelectDeleted: tt.fields.SelectDeleted,
+				BindType:      tt.fields.BindType,
+				TenantColumn:  tt.fields.TenantColumn,
+			}
+			if got := q.DeleteIfNotDeleted(); got != tt.want {
+				t.Errorf("QueryBuilder.DeleteIfNotDeleted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_DeleteWithReturning(t *testing.T) {
+	type fields struct {
+		Table        string
+		Columns      []string
+		BindType     BindParam
+		Dialect      SQLDialect
+		TenantColumn string
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		want      string
+		wantNamed string
+		wantErr   bool
+	}{
+		{"ok", fields{"users", []string{"id", "name", "deleted_at"}, DOLLAR, 0, ""},
+			"UPDATE users SET deleted_at = $1 WHERE id = $2 RETURNING id, name, deleted_at",
+			"UPDATE users SET deleted_at = :deleted_at WHERE id = :id RETURNING id, name, deleted_at", false},
+		{"ok tenant", fields{"users", []string{"id", "tenant_id", "deleted_at"}, DOLLAR, POSTGRES, "tenant_id"},
+			"UPDATE users SET deleted_at = $1 WHERE id = $2 AND tenant_id = $3 RETURNING id, tenant_id, deleted_at",
+			"UPDATE users SET deleted_at = :deleted_at WHERE id = :id AND tenant_id = :tenant_id RETURNING id, tenant_id, deleted_at", false},
+		{"ok sqlite", fields{"users", []string{"id", "name", "deleted_at"}, QUESTION, SQLITE, ""},
+			"UPDATE users SET deleted_at = ? WHERE id = ? RETURNING id, name, deleted_at",
+			"UPDATE users SET deleted_at = :deleted_at WHERE id = :id RETURNING id, name, deleted_at", false},
+		{"fail question", fields{"users", []string{"id", "name", "deleted_at"}, QUESTION, 0, ""}, "", "", true},
+		{"fail mysql", fields{"users", []string{"id", "name", "deleted_at"}, QUESTION, MYSQL, ""}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:        tt.fields.Table,
+				Columns:      tt.fields.Columns,
+				BindType:     tt.fields.BindType,
+				Dialect:      tt.fields.Dialect,
+				TenantColumn: tt.fields.TenantColumn,
+			}
+			got, err := q.DeleteWithReturning()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.DeleteWithReturning() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.DeleteWithReturning() = %v, want %v", got, tt.want)
+			}
+			got, err = q.NamedDeleteWithReturning()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.NamedDeleteWithReturning() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.wantNamed {
+				t.Errorf("QueryBuilder.NamedDeleteWithReturning() = %v, want %v", got, tt.wantNamed)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_ColumnGroups(t *testing.T) {
+	type fields struct {
+		Columns       []string
+		PrimaryKey    string
+		VersionColumn string
+	}
+	tests := []struct {
+		name         string
+		fields       fields
+		wantKeys     []string
+		wantWritable []string
+		wantReadonly []string
+	}{
+		{"ok", fields{[]string{"id", "name", "email", "created_at", "deleted_at"}, "id", ""}, []string{"id"}, []string{"name", "email", "deleted_at"}, []string{"created_at"}},
+		{"ok custom key", fields{[]string{"oid", "name"}, "oid", ""}, []string{"oid"}, []string{"name"}, nil},
+		{"ok no key", fields{[]string{"message", "created_at"}, "", ""}, nil, []string{"message"}, []string{"created_at"}},
+		{"ok version", fields{[]string{"id", "name", "version", "created_at"}, "", "version"}, []string{"id"}, []string{"name", "version"}, []string{"created_at"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         "users",
+				Columns:       tt.fields.Columns,
+				PrimaryKey:    tt.fields.PrimaryKey,
+				VersionColumn: tt.fields.VersionColumn,
+			}
+			gotKeys, gotWritable, gotReadonly := q.ColumnGroups()
+			if !reflect.DeepEqual(gotKeys, tt.wantKeys) {
+				t.Errorf("QueryBuilder.ColumnGroups() keys = %v, want %v", gotKeys, tt.wantKeys)
+			}
+			if !reflect.DeepEqual(gotWritable, tt.wantWritable) {
+				t.Errorf("QueryBuilder.ColumnGroups() writable = %v, want %v", gotWritable, tt.wantWritable)
+			}
+			if !reflect.DeepEqual(gotReadonly, tt.wantReadonly) {
+				t.Errorf("QueryBuilder.ColumnGroups() readonly = %v, want %v", gotReadonly, tt.wantReadonly)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_SelectForShare(t *testing.T) {
+	type fields struct {
+		BindType BindParam
+		Dialect  SQLDialect
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    string
+		wantErr bool
+	}{
+		{"postgres", fields{DOLLAR, 0}, "SELECT id, name, deleted_at FROM users WHERE id = $1 AND deleted_at IS NULL FOR SHARE", false},
+		{"mysql", fields{QUESTION, MYSQL}, "SELECT id, name, deleted_at FROM users WHERE id = ? AND deleted_at IS NULL LOCK IN SHARE MODE", false},
+		{"fail sqlite", fields{QUESTION, SQLITE}, "", true},
+		{"fail generic", fields{QUESTION, 0}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:    "users",
+				Columns:  []string{"id", "name", "deleted_at"},
+				BindType: tt.fields.BindType,
+				Dialect:  tt.fields.Dialect,
+			}
+			got, err := q.SelectForShare()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.SelectForShare() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.SelectForShare() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_HasPrimaryKey(t *testing.T) {
+	type fields struct {
+		Columns    []string
+		PrimaryKey string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   bool
+	}{
+		{"ok", fields{[]string{"id", "name"}, "id"}, true},
+		{"ok default", fields{[]string{"id", "name"}, ""}, true},
+		{"ok custom", fields{[]string{"oid", "name"}, "oid"}, true},
+		{"ok configured", fields{[]string{"name", "email"}, "id"}, true},
+		{"keyless default", fields{[]string{"message", "created_at"}, ""}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:      "logs",
+				Columns:    tt.fields.Columns,
+				PrimaryKey: tt.fields.PrimaryKey,
+			}
+			if got := q.HasPrimaryKey(); got != tt.want {
+				t.Errorf("QueryBuilder.HasPrimaryKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_keyless(t *testing.T) {
+	q := NewQueryBuilder("logs", []string{"message", "created_at"})
+	q.OrderByPrimaryKey = true
+	queries := []struct {
+		name string
+		fn   func() string
+		want string
+	}{
+		{"Insert", q.Insert, "INSERT INTO logs (message, created_at) VALUES ($1, $2)"},
+		{"InsertWithReturning", q.InsertWithReturning, "INSERT INTO logs (message, created_at) VALUES ($1, $2)"},
+		{"NamedInsertWithReturning", q.NamedInsertWithReturning, "INSERT INTO logs (message, created_at) VALUES (:message, :created_at)"},
+		{"InsertDefaultValues", q.InsertDefaultValues, "INSERT INTO logs DEFAULT VALUES"},
+		{"SelectAll", q.SelectAll, "SELECT message, created_at FROM logs"},
+		{"ListAndCountBy", func() string { s, _ := q.ListAndCountBy("message"); return s }, "SELECT message, created_at FROM logs WHERE message = $1 LIMIT $2 OFFSET $3"},
+	}
+	for _, tt := range queries {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(); got != tt.want {
+				t.Errorf("QueryBuilder.%s() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	errs := map[string]func() (string, error){
+		"SelectAsOf":               q.SelectAsOf,
+		"SelectForShare":           q.SelectForShare,
+		"SelectForUpdateOf":        func() (string, error) { return q.SelectForUpdateOf() },
+		"SelectAfter":              func() (string, error) { return q.SelectAfter("created_at") },
+		"UpdateReturningAll":       q.UpdateReturningAll,
+		"UpdateColumnIf":           func() (string, error) { return q.UpdateColumnIf("message", "message") },
+		"Increment":                func() (string, error) { return q.Increment("message") },
+		"ClaimNext":                func() (string, error) { return q.ClaimNext("message", "created_at") },
+		"UpdateSetNull":            func() (string, error) { return q.UpdateSetNull("message") },
+		"Upsert":                   func() (string, error) { return q.Upsert() },
+		"UpsertMany":               func() (string, error) { return q.UpsertMany(2) },
+		"Merge":                    func() (string, error) { return q.Merge("") },
+		"DeleteWithReturning":      q.DeleteWithReturning,
+		"DeleteReturningAll":       q.DeleteReturningAll,
+		"NamedDeleteWithReturning": q.NamedDeleteWithReturning,
+		"UpdateQuery": func() (string, error) {
+			r, err := q.UpdateQuery(testTable{})
+			return r.SQL, err
+		},
+		"UpdateChanged": func() (string, error) {
+			s, _, err := q.UpdateChanged(testTable{}, testTable{})
+			return s, err
+		},
+		"DeleteQuery": func() (string, error) {
+			r, err := q.DeleteQuery("1")
+			return r.SQL, err
+		},
+	}
+	for name, fn := range errs {
+		t.Run(name, func(t *testing.T) {
+			if got, err := fn(); !errors.Is(err, ErrUnknownColumn) || got != "" {
+				t.Errorf("QueryBuilder.%s() = %v, %v, want ErrUnknownColumn", name, got, err)
+			}
+		})
+	}
+
+	configured := &QueryBuilder{Table: "users", Columns: []string{"name", "email"}, PrimaryKey: "id", SelectDeleted: true, BindType: DOLLAR}
+	if got, want := configured.Select(), "SELECT name, email FROM users WHERE id = $1"; got != want {
+		t.Errorf("QueryBuilder.Select() = %v, want %v", got, want)
+	}
+	if got, want := configured.InsertWithReturning(), "INSERT INTO users (name, email) VALUES ($1, $2) RETURNING id"; got != want {
+		t.Errorf("QueryBuilder.InsertWithReturning() = %v, want %v", got, want)
+	}
+}
+
+func TestQueryBuilder_NamedType(t *testing.T) {
+	tests := []struct {
+		name      string
+		namedType NamedParam
+		fn        func(q *QueryBuilder) string
+		want      string
+	}{
+		{"NamedInsert", COLON, (*QueryBuilder).NamedInsert, "INSERT INTO users (id, tenant_id, name, created_at, deleted_at) VALUES (:id, :tenant_id, :name, :created_at, :deleted_at)"},
+		{"NamedInsert pgx", AT, (*QueryBuilder).NamedInsert, "INSERT INTO users (id, tenant_id, name, created_at, deleted_at) VALUES (@id, @tenant_id, @name, @created_at, @deleted_at)"},
+		{"NamedInsertWithReturning", COLON, (*QueryBuilder).NamedInsertWithReturning, "INSERT INTO users (tenant_id, name, created_at, deleted_at) VALUES (:tenant_id, :name, :created_at, :deleted_at) RETURNING id"},
+		{"NamedInsertWithReturning pgx", AT, (*QueryBuilder).NamedInsertWithReturning, "INSERT INTO users (tenant_id, name, created_at, deleted_at) VALUES (@tenant_id, @name, @created_at, @deleted_at) RETURNING id"},
+		{"NamedUpdate", COLON, (*QueryBuilder).NamedUpdate, "UPDATE users SET tenant_id = :tenant_id, name = :name, deleted_at = :deleted_at WHERE id = :id AND tenant_id = :tenant_id"},
+		{"NamedUpdate pgx", AT, (*QueryBuilder).NamedUpdate, "UPDATE users SET tenant_id = @tenant_id, name = @name, deleted_at = @deleted_at WHERE id = @id AND tenant_id = @tenant_id"},
+		{"NamedDeleteWithReturning pgx", AT, func(q *QueryBuilder) string { s, _ := q.NamedDeleteWithReturning(); return s },
+			"UPDATE users SET deleted_at = @deleted_at WHERE id = @id AND tenant_id = @tenant_id RETURNING id, tenant_id, name, created_at, deleted_at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewQueryBuilder("users", []string{"id", "tenant_id", "name", "created_at", "deleted_at"})
+			q.TenantColumn = "tenant_id"
+			q.NamedType = tt.namedType
+			if got := tt.fn(q); got != tt.want {
+				t.Errorf("QueryBuilder.%s() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_UpdateFrom(t *testing.T) {
+	type fields struct {
+		Dialect       SQLDialect
+		BindType      BindParam
+		TenantColumn  string
+		VersionColumn string
+	}
+	type args struct {
+		sourceTable string
+		setCols     []string
+		joinCol     string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{"ok", fields{0, DOLLAR, "", ""}, args{"staging", []string{"status"}, "id"}, "UPDATE users SET status = staging.status FROM staging WHERE users.id = staging.id", false},
+		{"ok alias", fields{POSTGRES, DOLLAR, "", ""}, args{"staging src", []string{"status", "name"}, "id"}, "UPDATE users SET status = src.status, name = src.name FROM staging src WHERE users.id = src.id", false},
+		{"ok all columns", fields{SQLITE, QUESTION, "", ""}, args{"staging", nil, "email"}, "UPDATE users SET tenant_id = staging.tenant_id, name = staging.name, email = staging.email, status = staging.status, version = staging.version FROM staging WHERE users.email = staging.email", false},
+		{"ok all columns tenant and version", fields{POSTGRES, DOLLAR, "tenant_id", "version"}, args{"staging src", nil, "id"}, "UPDATE users SET name = src.name, email = src.email, status = src.status, version = users.version + 1 FROM staging src WHERE users.id = src.id AND users.tenant_id = $1", false},
+		{"ok tenant", fields{0, DOLLAR, "tenant_id", ""}, args{"staging AS src", []string{"status"}, "id"}, "UPDATE users SET status = src.status FROM staging AS src WHERE users.id = src.id AND users.tenant_id = $1", false},
+		{"fail mysql", fields{MYSQL, QUESTION, "", ""}, args{"staging", []string{"status"}, "id"}, "", true},
+		{"fail set column", fields{0, DOLLAR, "", ""}, args{"staging", []string{"foo"}, "id"}, "", true},
+		{"fail join column", fields{0, DOLLAR, "", ""}, args{"staging", []string{"status"}, "foo"}, "", true},
+		{"fail id", fields{0, DOLLAR, "", ""}, args{"staging", []string{"id"}, "email"}, "", true},
+		{"fail created_at", fields{0, DOLLAR, "", ""}, args{"staging", []string{"created_at"}, "id"}, "", true},
+		{"fail tenant", fields{0, DOLLAR, "tenant_id", ""}, args{"staging", []string{"tenant_id"}, "id"}, "", true},
+		{"fail version", fields{0, DOLLAR, "", "version"}, args{"staging", []string{"version"}, "id"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         "users",
+				Columns:       []string{"id", "tenant_id", "name", "email", "status", "created_at", "version"},
+				BindType:      tt.fields.BindType,
+				Dialect:       tt.fields.Dialect,
+				TenantColumn:  tt.fields.TenantColumn,
+				VersionColumn: tt.fields.VersionColumn,
+			}
+			got, err := q.UpdateFrom(tt.args.sourceTable, tt.args.setCols, tt.args.joinCol)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.UpdateFrom() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.UpdateFrom() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_qualifiedSource(t *testing.T) {
+	q := &QueryBuilder{
+		Table:    "users",
+		Columns:  []string{"id", "name", "created_at", "profile.email"},
+		BindType: DOLLAR,
+		Dialect:  POSTGRES,
+	}
+	tests := map[string]func() (string, error){
+		"UpdateFrom":         func() (string, error) { return q.UpdateFrom("staging", []string{"profile.email"}, "id") },
+		"UpdateFrom default": func() (string, error) { return q.UpdateFrom("staging", nil, "id") },
+		"Upsert":             func() (string, error) { return q.Upsert() },
+		"UpsertMany":         func() (string, error) { return q.UpsertMany(2) },
+		"Merge":              func() (string, error) { return q.Merge("src") },
+	}
+	for name, fn := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got, err := fn(); err == nil || got != "" {
+				t.Errorf("QueryBuilder.%s() = %v, %v, want error", name, got, err)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_HasColumn(t *testing.T) {
+	q := NewQueryBuilder("users", []string{"id", "name", "email"})
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"id", true},
+		{"email", true},
+		{"deleted_at", false},
+		{"ID", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := q.HasColumn(tt.name); got != tt.want {
+				t.Errorf("QueryBuilder.HasColumn() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_ReturningColumns(t *testing.T) {
+	q := NewQueryBuilder("users", []string{"id", "name", "email"})
+	tests := []struct {
+		name  string
+		alias string
+		want  string
+	}{
+		{"alias", "u", "u.id, u.name, u.email"},
+		{"table", "users", "users.id, users.name, users.email"},
+		{"no alias", "", "id, name, email"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := q.ReturningColumns(tt.alias); got != tt.want {
+				t.Errorf("QueryBuilder.ReturningColumns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_qualify(t *testing.T) {
+	tests := []struct {
+		name  string
+		alias string
+		col   string
+		want  string
+	}{
+		{"ok", "u", "email", "u.email"},
+		{"ok no alias", "", "email", "email"},
+		{"ok qualified", "u", "data.email", "data.email"},
+		{"ok qualified no alias", "", "data.email", "data.email"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := qualify(tt.alias, tt.col); got != tt.want {
+				t.Errorf("qualify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_smallTables(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []string
+		want    []string
+	}{
+		{"one column", []string{"id"}, []string{
+			"SELECT id FROM tags WHERE id = $1",
+			"INSERT INTO tags (id) VALUES ($1)",
+			"INSERT INTO tags (id) VALUES (:id)",
+			"UPDATE tags SET name = $1 WHERE id = $2",
+		}},
+		{"two columns", []string{"user_id", "group_id"}, []string{
+			"SELECT user_id, group_id FROM tags WHERE id = $1",
+			"INSERT INTO tags (user_id, group_id) VALUES ($1, $2)",
+			"INSERT INTO tags (user_id, group_id) VALUES (:user_id, :group_id)",
+			"UPDATE tags SET name = $1 WHERE id = $2",
+		}},
+		{"three columns", []string{"id", "user_id", "group_id"}, []string{
+			"SELECT id, user_id, group_id FROM tags WHERE id = $1",
+			"INSERT INTO tags (id, user_id, group_id) VALUES ($1, $2, $3)",
+			"INSERT INTO tags (id, user_id, group_id) VALUES (:id, :user_id, :group_id)",
+			"UPDATE tags SET name = $1 WHERE id = $2",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewQueryBuilder("tags", tt.columns)
+			got := []string{q.Select(), q.Insert(), q.NamedInsert(), q.update([]string{"name"})}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryBuilder queries = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func benchmarkQueries(b *testing.B, columns []string) {
+	q := NewQueryBuilder("user_groups", columns)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _, _, _ = q.Queries()
+		_ = q.NamedInsert()
+		_ = q.NamedUpdate()
+	}
+}
+
+func BenchmarkQueryBuilder_OneColumn(b *testing.B) {
+	benchmarkQueries(b, []string{"id"})
+}
+
+func BenchmarkQueryBuilder_TwoColumns(b *testing.B) {
+	benchmarkQueries(b, []string{"user_id", "group_id"})
+}
+
+func BenchmarkQueryBuilder_ManyColumns(b *testing.B) {
+	benchmarkQueries(b, []string{"id", "name", "email", "created_at", "updated_at", "deleted_at"})
+}
+
+func TestQueryBuilder_SelectForUpdateOf(t *testing.T) {
+	type fields struct {
+		BindType BindParam
+		Dialect  SQLDialect
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		tables  []string
+		want    string
+		wantErr bool
+	}{
+		{"ok", fields{DOLLAR, 0}, []string{"users"}, "SELECT id, name, deleted_at FROM users WHERE id = $1 AND deleted_at IS NULL FOR UPDATE OF users", false},
+		{"ok default", fields{DOLLAR, POSTGRES}, nil, "SELECT id, name, deleted_at FROM users WHERE id = $1 AND deleted_at IS NULL FOR UPDATE OF users", false},
+		{"fail table", fields{DOLLAR, 0}, []string{"users", "groups"}, "", true},
+		{"fail mysql", fields{QUESTION, MYSQL}, nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:    "users",
+				Columns:  []string{"id", "name", "deleted_at"},
+				BindType: tt.fields.BindType,
+				Dialect:  tt.fields.Dialect,
+			}
+			got, err := q.SelectForUpdateOf(tt.tables...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.SelectForUpdateOf() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.SelectForUpdateOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_IncludingDeleted(t *testing.T) {
+	tests := []struct {
+		name          string
+		tenant        string
+		selectDeleted bool
+		wantSelect    string
+		wantSelectAll string
+	}{
+		{"ok", "", false, "SELECT id, name, deleted_at FROM users WHERE id = $1", "SELECT id, name, deleted_at FROM users"},
+		{"ok select deleted", "", true, "SELECT id, name, deleted_at FROM users WHERE id = $1", "SELECT id, name, deleted_at FROM users"},
+		{"ok tenant", "org_id", false, "SELECT id, name, deleted_at FROM users WHERE id = $1 AND org_id = $2", "SELECT id, name, deleted_at FROM users WHERE org_id = $1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         "users",
+				Columns:       []string{"id", "name", "deleted_at"},
+				SelectDeleted: tt.selectDeleted,
+				TenantColumn:  tt.tenant,
+			}
+			if got := q.SelectIncludingDeleted(); got != tt.wantSelect {
+				t.Errorf("QueryBuilder.SelectIncludingDeleted() = %v, want %v", got, tt.wantSelect)
+			}
+			if got := q.SelectAllIncludingDeleted(); got != tt.wantSelectAll {
+				t.Errorf("QueryBuilder.SelectAllIncludingDeleted() = %v, want %v", got, tt.wantSelectAll)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_SelectAsOf(t *testing.T) {
+	type fields struct {
+		BindType     BindParam
+		Dialect      SQLDialect
+		TenantColumn string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    string
+		wantErr bool
+	}{
+		{"ok", fields{DOLLAR, 0, ""}, "SELECT id, name, deleted_at FROM users FOR SYSTEM_TIME AS OF $1 WHERE id = $2 AND deleted_at IS NULL", false},
+		{"ok tenant", fields{DOLLAR, POSTGRES, "org_id"}, "SELECT id, name, deleted_at FROM users FOR SYSTEM_TIME AS OF $1 WHERE id = $2 AND org_id = $3 AND deleted_at IS NULL", false},
+		{"ok mysql", fields{QUESTION, MYSQL, ""}, "SELECT id, name, deleted_at FROM users FOR SYSTEM_TIME AS OF ? WHERE id = ? AND deleted_at IS NULL", false},
+		{"fail sqlite", fields{QUESTION, SQLITE, ""}, "", true},
+		{"fail generic", fields{QUESTION, 0, ""}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:        "users",
+				Columns:      []string{"id", "name", "deleted_at"},
+				BindType:     tt.fields.BindType,
+				Dialect:      tt.fields.Dialect,
+				TenantColumn: tt.fields.TenantColumn,
+			}
+			got, err := q.SelectAsOf()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.SelectAsOf() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.SelectAsOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_ValueExprs(t *testing.T) {
+	type want struct {
+		insert, insertWithReturning, namedInsert, namedInsertWithReturning, upsertInsert string
+	}
+	tests := []struct {
+		name     string
+		bindType BindParam
+		exprs    map[string]string
+		want     want
+	}{
+		{"ok", DOLLAR, map[string]string{"password": "crypt({}, gen_salt('bf'))", "created_at": "NOW()"}, want{
+			"INSERT INTO users (id, password, created_at) VALUES ($1, crypt($2, gen_salt('bf')), NOW())",
+			"INSERT INTO users (password, created_at) VALUES (crypt($1, gen_salt('bf')), NOW()) RETURNING id",
+			"INSERT INTO users (id, password, created_at) VALUES (:id, crypt(:password, gen_salt('bf')), NOW())",
+			"INSERT INTO users (password, created_at) VALUES (crypt(:password, gen_salt('bf')), NOW()) RETURNING id",
+			"INSERT INTO users (id, password, created_at) SELECT $1, crypt($2, gen_salt('bf')), NOW() WHERE NOT EXISTS (SELECT 1 FROM users WHERE id = $3)",
+		}},
+		{"ok question", QUESTION, map[string]string{"id": "NOW()"}, want{
+			"INSERT INTO users (id, password, created_at) VALUES (NOW(), ?, ?)",
+			"INSERT INTO users (password, created_at) VALUES (?, ?)",
+			"INSERT INTO users (id, password, created_at) VALUES (NOW(), :password, :created_at)",
+			"INSERT INTO users (password, created_at) VALUES (:password, :created_at)",
+			"INSERT INTO users (id, password, created_at) SELECT NOW(), ?, ? WHERE NOT EXISTS (SELECT 1 FROM users WHERE id = ?)",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:      "users",
+				Columns:    []string{"id", "password", "created_at"},
+				BindType:   tt.bindType,
+				ValueExprs: tt.exprs,
+			}
+			if got := q.Insert(); got != tt.want.insert {
+				t.Errorf("QueryBuilder.Insert() = %v, want %v", got, tt.want.insert)
+			}
+			if got := q.InsertWithReturning(); got != tt.want.insertWithReturning {
+				t.Errorf("QueryBuilder.InsertWithReturning() = %v, want %v", got, tt.want.insertWithReturning)
+			}
+			if got := q.NamedInsert(); got != tt.want.namedInsert {
+				t.Errorf("QueryBuilder.NamedInsert() = %v, want %v", got, tt.want.namedInsert)
+			}
+			if got := q.NamedInsertWithReturning(); got != tt.want.namedInsertWithReturning {
+				t.Errorf("QueryBuilder.NamedInsertWithReturning() = %v, want %v", got, tt.want.namedInsertWithReturning)
+			}
+			if _, got := q.UpsertPortable(); got != tt.want.upsertInsert {
+				t.Errorf("QueryBuilder.UpsertPortable() = %v, want %v", got, tt.want.upsertInsert)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_Upsert(t *testing.T) {
+	type fields struct {
+		BindType BindParam
+		Dialect  SQLDialect
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		opts    []UpsertOption
+		want    string
+		wantErr bool
+	}{
+		{"ok", fields{DOLLAR, 0}, nil, "INSERT INTO users (id, name, created_at, updated_at) VALUES ($1, $2, $3, $4) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at", false},
+		{"ok only if newer", fields{DOLLAR, POSTGRES}, []UpsertOption{OnlyIfNewer("updated_at")}, "INSERT INTO users (id, name, created_at, updated_at) VALUES ($1, $2, $3, $4) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at WHERE EXCLUDED.updated_at > users.updated_at", false},
+		{"ok return inserted", fields{DOLLAR, 0}, []UpsertOption{ReturnInserted()}, "INSERT INTO users (id, name, created_at, updated_at) VALUES ($1, $2, $3, $4) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at RETURNING id, name, created_at, updated_at, (xmax = 0) AS inserted", false},
+		{"ok sqlite", fields{QUESTION, SQLITE}, nil, "INSERT INTO users (id, name, created_at, updated_at) VALUES (?, ?, ?, ?) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at", false},
+		{"fail column", fields{DOLLAR, 0}, []UpsertOption{OnlyIfNewer("modified_at")}, "", true},
+		{"fail return inserted sqlite", fields{QUESTION, SQLITE}, []UpsertOption{ReturnInserted()}, "", true},
+		{"fail mysql", fields{QUESTION, MYSQL}, nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:    "users",
+				Columns:  []string{"id", "name", "created_at", "updated_at"},
+				BindType: tt.fields.BindType,
+				Dialect:  tt.fields.Dialect,
+			}
+			got, err := q.Upsert(tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.Upsert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.Upsert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_Upsert_tenant(t *testing.T) {
+	q := &QueryBuilder{
+		Table:        "users",
+		Columns:      []string{"id", "org_id", "name", "updated_at"},
+		TenantColumn: "org_id",
+	}
+	tests := []struct {
+		name string
+		opts []UpsertOption
+		want string
+	}{
+		{"ok", nil, "INSERT INTO users (id, org_id, name, updated_at) VALUES ($1, $2, $3, $4) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at WHERE users.org_id = EXCLUDED.org_id"},
+		{"ok only if newer", []UpsertOption{OnlyIfNewer("updated_at")}, "INSERT INTO users (id, org_id, name, updated_at) VALUES ($1, $2, $3, $4) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at WHERE EXCLUDED.updated_at > users.updated_at AND users.org_id = EXCLUDED.org_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := q.Upsert(tt.opts...)
+			if err != nil {
+				t.Fatalf("QueryBuilder.Upsert() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.Upsert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	want := "INSERT INTO users (id, org_id, name, updated_at) VALUES ($1, $2, $3, $4), ($5, $6, $7, $8) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at WHERE users.org_id = EXCLUDED.org_id"
+	if got, err := q.UpsertMany(2); err != nil || got != want {
+		t.Errorf("QueryBuilder.UpsertMany() = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestQueryBuilder_VersionColumn(t *testing.T) {
+	q := &QueryBuilder{
+		Table:         "users",
+		Columns:       []string{"id", "name", "login_count", "version"},
+		VersionColumn: "version",
+	}
+	sqlserver := &QueryBuilder{
+		Table:         "users",
+		Columns:       []string{"id", "name", "login_count", "version"},
+		BindType:      QUESTION,
+		Dialect:       SQLSERVER,
+		VersionColumn: "version",
+	}
+	tests := []struct {
+		name string
+		fn   func() (string, error)
+		want string
+	}{
+		{"Upsert", func() (string, error) { return q.Upsert() }, "INSERT INTO users (id, name, login_count, version) VALUES ($1, $2, $3, $4) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, login_count = EXCLUDED.login_count, version = users.version + 1"},
+		{"UpsertMany", func() (string, error) { return q.UpsertMany(2) }, "INSERT INTO users (id, name, login_count, version) VALUES ($1, $2, $3, $4), ($5, $6, $7, $8) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, login_count = EXCLUDED.login_count, version = users.version + 1"},
+		{"Merge", func() (string, error) { return q.Merge("") }, "MERGE INTO users USING (VALUES ($1, $2, $3, $4)) AS src (id, name, login_count, version) ON users.id = src.id WHEN MATCHED THEN UPDATE SET name = src.name, login_count = src.login_count, version = users.version + 1 WHEN NOT MATCHED THEN INSERT (id, name, login_count, version) VALUES (src.id, src.name, src.login_count, src.version)"},
+		{"Merge sqlserver", func() (string, error) { return sqlserver.Merge("") }, "MERGE INTO users USING (VALUES (?, ?, ?, ?)) AS src (id, name, login_count, version) ON users.id = src.id WHEN MATCHED THEN UPDATE SET name = src.name, login_count = src.login_count, version = users.version + 1 WHEN NOT MATCHED THEN INSERT (id, name, login_count, version) VALUES (src.id, src.name, src.login_count, src.version);"},
+		{"Increment", func() (string, error) { return q.Increment("login_count") }, "UPDATE users SET login_count = login_count + $1, version = version + 1 WHERE id = $2"},
+		{"UpdateColumnIf", func() (string, error) { return q.UpdateColumnIf("name", "name") }, "UPDATE users SET name = $1, version = version + 1 WHERE id = $2 AND name = $3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn()
+			if err != nil {
+				t.Fatalf("QueryBuilder.%s() error = %v", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.%s() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_InsertDefaultsReturningAll(t *testing.T) {
+	type fields struct {
+		BindType      BindParam
+		Dialect       SQLDialect
+		ReturningStar bool
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		skip    []string
+		want    string
+		wantErr bool
+	}{
+		{"ok", fields{DOLLAR, 0, false}, []string{"id", "created_at"}, "INSERT INTO users (name, email) VALUES ($1, $2) RETURNING id, name, email, created_at", false},
+		{"ok middle", fields{DOLLAR, 0, false}, []string{"name"}, "INSERT INTO users (id, email, created_at) VALUES ($1, $2, $3) RETURNING id, name, email, created_at", false},
+		{"ok no skip", fields{DOLLAR, 0, true}, nil, "INSERT INTO users (id, name, email, created_at) VALUES ($1, $2, $3, $4) RETURNING *", false},
+		{"ok sqlite", fields{QUESTION, SQLITE, false}, []string{"id"}, "INSERT INTO users (name, email, created_at) VALUES (?, ?, ?) RETURNING id, name, email, created_at", false},
+		{"fail column", fields{DOLLAR, 0, false}, []string{"updated_at"}, "", true},
+		{"fail all columns", fields{DOLLAR, 0, false}, []string{"id", "name", "email", "created_at"}, "", true},
+		{"fail mysql", fields{QUESTION, MYSQL, false}, []string{"id"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         "users",
+				Columns:       []string{"id", "name", "email", "created_at"},
+				BindType:      tt.fields.BindType,
+				Dialect:       tt.fields.Dialect,
+				ReturningStar: tt.fields.ReturningStar,
+			}
+			got, err := q.InsertDefaultsReturningAll(tt.skip...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.InsertDefaultsReturningAll() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.InsertDefaultsReturningAll() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_InsertDefaultValues(t *testing.T) {
+	type fields struct {
+		BindType   BindParam
+		Dialect    SQLDialect
+		PrimaryKey string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{"ok", fields{DOLLAR, 0, ""}, "INSERT INTO users DEFAULT VALUES RETURNING id"},
+		{"ok primary key", fields{DOLLAR, POSTGRES, "oid"}, "INSERT INTO users DEFAULT VALUES RETURNING oid"},
+		{"ok sqlite", fields{QUESTION, SQLITE, ""}, "INSERT INTO users DEFAULT VALUES RETURNING id"},
+		{"ok mysql", fields{QUESTION, MYSQL, ""}, "INSERT INTO users () VALUES ()"},
+		{"ok sqlserver", fields{DOLLAR, SQLSERVER, ""}, "INSERT INTO users DEFAULT VALUES"},
+		{"ok generic", fields{QUESTION, 0, ""}, "INSERT INTO users DEFAULT VALUES"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:      "users",
+				Columns:    []string{"id", "oid", "created_at"},
+				BindType:   tt.fields.BindType,
+				Dialect:    tt.fields.Dialect,
+				PrimaryKey: tt.fields.PrimaryKey,
+			}
+			if got := q.InsertDefaultValues(); got != tt.want {
+				t.Errorf("QueryBuilder.InsertDefaultValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_UpdateSetNull(t *testing.T) {
+	type fields struct {
+		BindType      BindParam
+		TenantColumn  string
+		VersionColumn string
+	}
+	tests := []struct {
+		name     string
+		fields   fields
+		columns  []string
+		nullCols []string
+		want     string
+		wantErr  bool
+	}{
+		{"ok", fields{DOLLAR, "", ""}, nil, []string{"assignee_id"}, "UPDATE users SET assignee_id = NULL WHERE id = $1", false},
+		{"ok multiple", fields{DOLLAR, "", ""}, nil, []string{"assignee_id", "assigned_at"}, "UPDATE users SET assignee_id = NULL, assigned_at = NULL WHERE id = $1", false},
+		{"ok columns", fields{DOLLAR, "", ""}, []string{"status"}, []string{"assignee_id"}, "UPDATE users SET status = $1, assignee_id = NULL WHERE id = $2", false},
+		{"ok tenant", fields{QUESTION, "org_id", ""}, []string{"status"}, []string{"assignee_id"}, "UPDATE users SET status = ?, assignee_id = NULL WHERE id = ? AND org_id = ?", false},
+		{"ok version", fields{DOLLAR, "", "version"}, nil, []string{"assignee_id"}, "UPDATE users SET assignee_id = NULL, version = version + 1 WHERE id = $1 AND version = $2", false},
+		{"fail column", fields{DOLLAR, "", ""}, nil, []string{"owner_id"}, "", true},
+		{"fail bound column", fields{DOLLAR, "", ""}, []string{"state"}, []string{"assignee_id"}, "", true},
+		{"fail no columns", fields{DOLLAR, "", ""}, []string{"status"}, nil, "", true},
+		{"fail overlap", fields{DOLLAR, "", ""}, []string{"status", "id"}, []string{"status"}, "", true},
+		{"fail duplicate", fields{DOLLAR, "", ""}, nil, []string{"assignee_id", "assignee_id"}, "", true},
+		{"fail primary key", fields{DOLLAR, "", ""}, []string{"id"}, []string{"assignee_id"}, "", true},
+		{"fail null primary key", fields{DOLLAR, "", ""}, nil, []string{"id"}, "", true},
+		{"fail created at", fields{DOLLAR, "", ""}, nil, []string{"created_at"}, "", true},
+		{"fail version", fields{DOLLAR, "", "version"}, nil, []string{"version"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         "users",
+				Columns:       []string{"id", "status", "assignee_id", "assigned_at", "version", "created_at"},
+				BindType:      tt.fields.BindType,
+				TenantColumn:  tt.fields.TenantColumn,
+				VersionColumn: tt.fields.VersionColumn,
+			}
+			var got string
+			var err error
+			if tt.columns == nil {
+				got, err = q.UpdateSetNull(tt.nullCols...)
+			} else {
+				got, err = q.UpdateColumnsSetNull(tt.columns, tt.nullCols)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.UpdateSetNull() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.UpdateSetNull() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_UpdateColumnIf(t *testing.T) {
+	type fields struct {
+		BindType      BindParam
+		TenantColumn  string
+		VersionColumn string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		setCol  string
+		condCol string
+		want    string
+		wantErr bool
+	}{
+		{"ok", fields{DOLLAR, "", ""}, "status", "status", "UPDATE users SET status = $1 WHERE id = $2 AND status = $3", false},
+		{"ok other column", fields{DOLLAR, "", ""}, "status", "version", "UPDATE users SET status = $1 WHERE id = $2 AND version = $3", false},
+		{"ok tenant", fields{DOLLAR, "org_id", ""}, "status", "status", "UPDATE users SET status = $1 WHERE id = $2 AND status = $3 AND org_id = $4", false},
+		{"ok version", fields{DOLLAR, "", "version"}, "status", "status", "UPDATE users SET status = $1, version = version + 1 WHERE id = $2 AND status = $3", false},
+		{"ok question", fields{QUESTION, "", ""}, "status", "status", "UPDATE users SET status = ? WHERE id = ? AND status = ?", false},
+		{"fail set column", fields{DOLLAR, "", ""}, "state", "status", "", true},
+		{"fail cond column", fields{DOLLAR, "", ""}, "status", "state", "", true},
+		{"fail id", fields{DOLLAR, "", ""}, "id", "status", "", true},
+		{"fail created_at", fields{DOLLAR, "", ""}, "created_at", "status", "", true},
+		{"fail version", fields{DOLLAR, "", "version"}, "version", "status", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         "users",
+				Columns:       []string{"id", "status", "created_at", "version"},
+				BindType:      tt.fields.BindType,
+				TenantColumn:  tt.fields.TenantColumn,
+				VersionColumn: tt.fields.VersionColumn,
+			}
+			got, err := q.UpdateColumnIf(tt.setCol, tt.condCol)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.UpdateColumnIf() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.UpdateColumnIf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_Increment(t *testing.T) {
+	type fields struct {
+		BindType      BindParam
+		TenantColumn  string
+		VersionColumn string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		col     string
+		want    string
+		wantErr bool
+	}{
+		{"ok", fields{DOLLAR, "", ""}, "login_count", "UPDATE users SET login_count = login_count + $1 WHERE id = $2", false},
+		{"ok tenant", fields{DOLLAR, "org_id", ""}, "login_count", "UPDATE users SET login_count = login_count + $1 WHERE id = $2 AND org_id = $3", false},
+		{"ok question", fields{QUESTION, "", ""}, "login_count", "UPDATE users SET login_count = login_count + ? WHERE id = ?", false},
+		{"ok version", fields{DOLLAR, "", "version"}, "login_count", "UPDATE users SET login_count = login_count + $1, version = version + 1 WHERE id = $2", false},
+		{"fail column", fields{DOLLAR, "", ""}, "logins", "", true},
+		{"fail id", fields{DOLLAR, "", ""}, "id", "", true},
+		{"fail created_at", fields{DOLLAR, "", ""}, "created_at", "", true},
+		{"fail version", fields{DOLLAR, "", "version"}, "version", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         "users",
+				Columns:       []string{"id", "name", "login_count", "created_at", "version"},
+				BindType:      tt.fields.BindType,
+				TenantColumn:  tt.fields.TenantColumn,
+				VersionColumn: tt.fields.VersionColumn,
+			}
+			got, err := q.Increment(tt.col)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.Increment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.Increment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_ClaimNext(t *testing.T) {
+	type fields struct {
+		BindType      BindParam
+		Dialect       SQLDialect
+		TenantColumn  string
+		SelectDeleted bool
+		VersionColumn string
+	}
+	tests := []struct {
+		name     string
+		fields   fields
+		status   string
+		orderCol string
+		want     string
+		wantErr  bool
+	}{
+		{"ok", fields{DOLLAR, 0, "", false, ""}, "status", "created_at", "UPDATE jobs SET status = $1 WHERE id = (SELECT id FROM jobs WHERE status = $2 AND deleted_at IS NULL ORDER BY created_at LIMIT 1 FOR UPDATE SKIP LOCKED) RETURNING id, status, created_at, deleted_at, version", false},
+		{"ok priority", fields{DOLLAR, POSTGRES, "", true, ""}, "status", "id", "UPDATE jobs SET status = $1 WHERE id = (SELECT id FROM jobs WHERE status = $2 ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED) RETURNING id, status, created_at, deleted_at, version", false},
+		{"ok tenant", fields{DOLLAR, 0, "org_id", true, ""}, "status", "created_at", "UPDATE jobs SET status = $1 WHERE id = (SELECT id FROM jobs WHERE status = $2 AND org_id = $3 ORDER BY created_at LIMIT 1 FOR UPDATE SKIP LOCKED) RETURNING id, status, created_at, deleted_at, version", false},
+		{"ok version", fields{DOLLAR, 0, "", true, "version"}, "status", "created_at", "UPDATE jobs SET status = $1, version = version + 1 WHERE id = (SELECT id FROM jobs WHERE status = $2 ORDER BY created_at LIMIT 1 FOR UPDATE SKIP LOCKED) RETURNING id, status, created_at, deleted_at, version", false},
+		{"fail status", fields{DOLLAR, 0, "", false, ""}, "state", "created_at", "", true},
+		{"fail order", fields{DOLLAR, 0, "", false, ""}, "status", "priority", "", true},
+		{"fail version status", fields{DOLLAR, 0, "", true, "version"}, "version", "created_at", "", true},
+		{"fail mysql", fields{QUESTION, MYSQL, "", false, ""}, "status", "created_at", "", true},
+		{"fail sqlite", fields{QUESTION, SQLITE, "", false, ""}, "status", "created_at", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         "jobs",
+				Columns:       []string{"id", "status", "created_at", "deleted_at", "version"},
+				BindType:      tt.fields.BindType,
+				Dialect:       tt.fields.Dialect,
+				TenantColumn:  tt.fields.TenantColumn,
+				SelectDeleted: tt.fields.SelectDeleted,
+				VersionColumn: tt.fields.VersionColumn,
+			}
+			got, err := q.ClaimNext(tt.status, tt.orderCol)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.ClaimNext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.ClaimNext() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_Merge(t *testing.T) {
+	type fields struct {
+		BindType     BindParam
+		Dialect      SQLDialect
+		TenantColumn string
+	}
+	tests := []struct {
+		name        string
+		fields      fields
+		sourceAlias string
+		want        string
+		wantErr     bool
+	}{
+		{"ok", fields{QUESTION, SQLSERVER, ""}, "s", "MERGE INTO users USING (VALUES (?, ?, ?)) AS s (id, name, created_at) ON users.id = s.id WHEN MATCHED THEN UPDATE SET name = s.name WHEN NOT MATCHED THEN INSERT (id, name, created_at) VALUES (s.id, s.name, s.created_at);", false},
+		{"ok default alias", fields{DOLLAR, POSTGRES, ""}, "", "MERGE INTO users USING (VALUES ($1, $2, $3)) AS src (id, name, created_at) ON users.id = src.id WHEN MATCHED THEN UPDATE SET name = src.name WHEN NOT MATCHED THEN INSERT (id, name, created_at) VALUES (src.id, src.name, src.created_at)", false},
+		{"ok tenant", fields{DOLLAR, POSTGRES, "name"}, "", "MERGE INTO users USING (VALUES ($1, $2, $3)) AS src (id, name, created_at) ON users.id = src.id AND users.name = src.name WHEN MATCHED THEN UPDATE SET name = src.name WHEN NOT MATCHED THEN INSERT (id, name, created_at) VALUES (src.id, src.name, src.created_at)", false},
+		{"fail mysql", fields{QUESTION, MYSQL, ""}, "", "", true},
+		{"fail sqlite", fields{QUESTION, SQLITE, ""}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:        "users",
+				Columns:      []string{"id", "name", "created_at"},
+				BindType:     tt.fields.BindType,
+				Dialect:      tt.fields.Dialect,
+				TenantColumn: tt.fields.TenantColumn,
+			}
+			got, err := q.Merge(tt.sourceAlias)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.Merge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.Merge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_UpsertMany(t *testing.T) {
+	type fields struct {
+		BindType   BindParam
+		Dialect    SQLDialect
+		ValueExprs map[string]string
+		Pretty     bool
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		rows    int
+		opts    []UpsertOption
+		want    string
+		wantErr bool
+	}{
+		{"ok pretty", fields{DOLLAR, 0, nil, true}, 2, nil, "INSERT INTO users (id, name, updated_at)\nVALUES\n  ($1, $2, $3),\n  ($4, $5, $6)\nON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at", false},
+		{"ok pretty one", fields{DOLLAR, 0, nil, true}, 1, nil, "INSERT INTO users (id, name, updated_at) VALUES ($1, $2, $3) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at", false},
+		{"ok one", fields{DOLLAR, 0, nil, false}, 1, nil, "INSERT INTO users (id, name, updated_at) VALUES ($1, $2, $3) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at", false},
+		{"ok two", fields{DOLLAR, 0, nil, false}, 2, nil, "INSERT INTO users (id, name, updated_at) VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at", false},
+		{"ok three", fields{DOLLAR, POSTGRES, nil, false}, 3, nil, "INSERT INTO users (id, name, updated_at) VALUES ($1, $2, $3), ($4, $5, $6), ($7, $8, $9) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at", false},
+		{"ok value exprs", fields{DOLLAR, 0, map[string]string{"updated_at": "NOW()"}, false}, 2, nil, "INSERT INTO users (id, name, updated_at) VALUES ($1, $2, NOW()), ($3, $4, NOW()) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at", false},
+		{"ok only if newer", fields{DOLLAR, 0, nil, false}, 2, []UpsertOption{OnlyIfNewer("updated_at")}, "INSERT INTO users (id, name, updated_at) VALUES ($1, $2, $3), ($4, $5, $6) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at WHERE EXCLUDED.updated_at > users.updated_at", false},
+		{"ok sqlite", fields{QUESTION, SQLITE, nil, false}, 2, nil, "INSERT INTO users (id, name, updated_at) VALUES (?, ?, ?), (?, ?, ?) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at", false},
+		{"fail rows", fields{DOLLAR, 0, nil, false}, 0, nil, "", true},
+		{"fail mysql", fields{QUESTION, MYSQL, nil, false}, 2, nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:      "users",
+				Columns:    []string{"id", "name", "updated_at"},
+				BindType:   tt.fields.BindType,
+				Dialect:    tt.fields.Dialect,
+				ValueExprs: tt.fields.ValueExprs,
+				Pretty:     tt.fields.Pretty,
+			}
+			got, err := q.UpsertMany(tt.rows, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.UpsertMany() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.UpsertMany() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrependCTE(t *testing.T) {
+	type args struct {
+		name string
+		cte  string
+		main string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{"ok", args{"active", "SELECT id FROM users WHERE active = $1", "SELECT id, name FROM users WHERE id IN (SELECT id FROM active)"}, "WITH active AS (SELECT id FROM users WHERE active = $1) SELECT id, name FROM users WHERE id IN (SELECT id FROM active)"},
+		{"ok chain", args{"a", "SELECT 1", "WITH b AS (SELECT 2) SELECT * FROM a, b"}, "WITH a AS (SELECT 1), b AS (SELECT 2) SELECT * FROM a, b"},
+		{"ok chain lowercase", args{"a", "SELECT 1", "with b AS (SELECT 2) SELECT * FROM a, b"}, "WITH a AS (SELECT 1), b AS (SELECT 2) SELECT * FROM a, b"},
+		{"ok recursive", args{"a", "SELECT 1", "WITH RECURSIVE b AS (SELECT 2) SELECT * FROM a, b"}, "WITH RECURSIVE a AS (SELECT 1), b AS (SELECT 2) SELECT * FROM a, b"},
+		{"ok without", args{"a", "SELECT 1", "WITHOUT"}, "WITH a AS (SELECT 1) WITHOUT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PrependCTE(tt.args.name, tt.args.cte, tt.args.main); got != tt.want {
+				t.Errorf("PrependCTE() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_ByIDClause(t *testing.T) {
+	type fields struct {
+		BindType      BindParam
+		SelectDeleted bool
+		TenantColumn  string
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		startBind int
+		want      string
+	}{
+		{"ok", fields{DOLLAR, false, ""}, 1, "id = $1 AND deleted_at IS NULL"},
+		{"ok start", fields{DOLLAR, false, ""}, 3, "id = $3 AND deleted_at IS NULL"},
+		{"ok select deleted", fields{DOLLAR, true, ""}, 2, "id = $2"},
+		{"ok tenant", fields{DOLLAR, false, "org_id"}, 2, "id = $2 AND org_id = $3 AND deleted_at IS NULL"},
+		{"ok question", fields{QUESTION, true, "org_id"}, 2, "id = ? AND org_id = ?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         "users",
+				Columns:       []string{"id", "name", "deleted_at"},
+				BindType:      tt.fields.BindType,
+				SelectDeleted: tt.fields.SelectDeleted,
+				TenantColumn:  tt.fields.TenantColumn,
+			}
+			if got := q.ByIDClause(tt.startBind); got != tt.want {
+				t.Errorf("QueryBuilder.ByIDClause() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_Casts(t *testing.T) {
+	casts := map[string]string{"id": "uuid", "status": "status_enum", "tags": "text[]"}
+	tests := []struct {
+		name     string
+		bindType BindParam
+		fn       func(q *QueryBuilder) string
+		want     string
+	}{
+		{"Select", DOLLAR, (*QueryBuilder).Select, "SELECT id, status, tags FROM users WHERE id = $1::uuid"},
+		{"Insert", DOLLAR, (*QueryBuilder).Insert, "INSERT INTO users (id, status, tags) VALUES ($1::uuid, $2::status_enum, $3::text[])"},
+		{"InsertWithReturning", DOLLAR, (*QueryBuilder).InsertWithReturning, "INSERT INTO users (status, tags) VALUES ($1::status_enum, $2::text[]) RETURNING id"},
+		{"Update", DOLLAR, (*QueryBuilder).Update, "UPDATE users SET status = $1::status_enum, tags = $2::text[] WHERE id = $3::uuid"},
+		{"Delete", DOLLAR, (*QueryBuilder).HardDelete, "DELETE FROM users WHERE id = $1::uuid"},
+		{"SelectBy", DOLLAR, func(q *QueryBuilder) string { return q.SelectBy("status", "tags") }, "SELECT id, status, tags FROM users WHERE status = $1::status_enum AND tags = $2::text[]"},
+		{"SelectIn", DOLLAR, func(q *QueryBuilder) string { return q.SelectIn("status", 2) }, "SELECT id, status, tags FROM users WHERE status IN ($1::status_enum, $2::status_enum)"},
+		{"SelectWhere", DOLLAR, func(q *QueryBuilder) string {
+			s, _ := q.SelectWhere(Eq("status", "active"))
+			return s
+		}, "SELECT id, status, tags FROM users WHERE status = $1::status_enum"},
+		{"Insert question", QUESTION, (*QueryBuilder).Insert, "INSERT INTO users (id, status, tags) VALUES (?, ?, ?)"},
+		{"SelectBy question", QUESTION, func(q *QueryBuilder) string { return q.SelectBy("status") }, "SELECT id, status, tags FROM users WHERE status = ?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         "users",
+				Columns:       []string{"id", "status", "tags"},
+				SelectDeleted: true,
+				BindType:      tt.bindType,
+				Casts:         casts,
+			}
+			if got := tt.fn(q); got != tt.want {
+				t.Errorf("QueryBuilder.%s() = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_ValuesTuple(t *testing.T) {
+	type fields struct {
+		Columns  []string
+		BindType BindParam
+		Casts    map[string]string
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		startBind int
+		want      string
+		wantNamed string
+	}{
+		{"ok", fields{[]string{"id", "name", "email"}, DOLLAR, nil}, 1, "($1, $2, $3)", "(:id, :name, :email)"},
+		{"ok start", fields{[]string{"id", "name", "email"}, DOLLAR, nil}, 4, "($4, $5, $6)", "(:id, :name, :email)"},
+		{"ok one column", fields{[]string{"id"}, DOLLAR, nil}, 2, "($2)", "(:id)"},
+		{"ok two columns", fields{[]string{"id", "name"}, DOLLAR, nil}, 3, "($3, $4)", "(:id, :name)"},
+		{"ok casts", fields{[]string{"id", "name"}, DOLLAR, map[string]string{"id": "uuid"}}, 3, "($3::uuid, $4)", "(:id, :name)"},
+		{"ok question", fields{[]string{"id", "name", "email"}, QUESTION, nil}, 4, "(?, ?, ?)", "(:id, :name, :email)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:    "users",
+				Columns:  tt.fields.Columns,
+				BindType: tt.fields.BindType,
+				Casts:    tt.fields.Casts,
+			}
+			if got := q.ValuesTuple(tt.startBind); got != tt.want {
+				t.Errorf("QueryBuilder.ValuesTuple() = %v, want %v", got, tt.want)
+			}
+			if got := q.NamedValuesTuple(); got != tt.wantNamed {
+				t.Errorf("QueryBuilder.NamedValuesTuple() = %v, want %v", got, tt.wantNamed)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_bindTypeOverride(t *testing.T) {
+	q := &QueryBuilder{
+		Table:        "users",
+		Columns:      []string{"id", "name", "created_at", "deleted_at"},
+		BindType:     DOLLAR,
+		TenantColumn: "org_id",
+	}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"SelectWith", q.SelectWith(QUESTION), "SELECT id, name, created_at, deleted_at FROM users WHERE id = ? AND org_id = ? AND deleted_at IS NULL"},
+		{"InsertWith", q.InsertWith(QUESTION), "INSERT INTO users (id, name, created_at, deleted_at) VALUES (?, ?, ?, ?)"},
+		{"UpdateWith", q.UpdateWith(QUESTION), "UPDATE users SET name = ?, deleted_at = ? WHERE id = ? AND org_id = ?"},
+		{"DeleteWith", q.DeleteWith(QUESTION), "UPDATE users SET deleted_at = ? WHERE id = ? AND org_id = ?"},
+		{"SelectWith dollar", q.SelectWith(DOLLAR), "SELECT id, name, created_at, deleted_at FROM users WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL"},
+		{"Select", q.Select(), "SELECT id, name, created_at, deleted_at FROM users WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("QueryBuilder.%s() = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_ReadOnly(t *testing.T) {
+	q := Must(testTable{}, ReadOnlyTable(true))
+	if got, want := q.Select(), "SELECT id, name, email FROM users WHERE id = $1"; got != want {
+		t.Errorf("QueryBuilder.Select() = %v, want %v", got, want)
+	}
+	if got, want := q.SelectAll(), "SELECT id, name, email FROM users"; got != want {
+		t.Errorf("QueryBuilder.SelectAll() = %v, want %v", got, want)
+	}
+
+	panics := map[string]func() string{
+		"Insert":                   q.Insert,
+		"InsertWithReturning":      q.InsertWithReturning,
+		"NamedInsert":              q.NamedInsert,
+		"NamedInsertWithReturning": q.NamedInsertWithReturning,
+		"Update":                   q.Update,
+		"NamedUpdate":              q.NamedUpdate,
+		"Delete":                   q.Delete,
+		"DeleteIfNotDeleted":       q.DeleteIfNotDeleted,
+		"HardDelete":               q.HardDelete,
+		"InsertFromSelect":         func() string { return q.InsertFromSelect("SELECT 1") },
+		"PurgeBefore":              q.PurgeBefore,
+		"Restore":                  q.Restore,
+		"InsertDefaultValues":      q.InsertDefaultValues,
+		"CascadeDelete":            func() string { return q.CascadeDelete("orders", "user_id") },
+		"UpsertPortable": func() string {
+			s, _ := q.UpsertPortable()
+			return s
+		},
+	}
+	for name, fn := range panics {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("QueryBuilder.%s() did not panic", name)
+				}
+			}()
+			fn()
+		})
+	}
+
+	errs := map[string]func() (string, error){
+		"UpdateFrom":            func() (string, error) { return q.UpdateFrom("staging s", []string{"name"}, "id") },
+		"InsertReturningAll":    q.InsertReturningAll,
+		"UpdateReturningAll":    q.UpdateReturningAll,
+		"InsertReturning":       func() (string, error) { return q.InsertReturning("id") },
+		"InsertReturningColumn": func() (string, error) { return q.InsertReturningColumn("id") },
+		"Upsert":                func() (string, error) { return q.Upsert() },
+		"UpsertMany":            func() (string, error) { return q.UpsertMany(2) },
+		"Merge":                 func() (string, error) { return q.Merge("") },
+		"Increment":             func() (string, error) { return q.Increment("name") },
+		"ClaimNext":             func() (string, error) { return q.ClaimNext("name", "id") },
+		"UpdateColumnIf":        func() (string, error) { return q.UpdateColumnIf("name", "name") },
+		"UpdateSetNull":         func() (string, error) { return q.UpdateSetNull("name") },
+		"InsertDefaultsReturningAll": func() (string, error) {
+			return q.InsertDefaultsReturningAll("id")
+		},
+		"DeleteWithReturning":      q.DeleteWithReturning,
+		"DeleteReturningAll":       q.DeleteReturningAll,
+		"NamedDeleteWithReturning": q.NamedDeleteWithReturning,
+		"InsertQuery": func() (string, error) {
+			r, err := q.InsertQuery(testTable{})
+			return r.SQL, err
+		},
+		"NamedInsertPositional": func() (string, error) {
+			s, _, err := q.NamedInsertPositional(testTable{})
+			return s, err
+		},
+		"DeleteQuery": func() (string, error) {
+			r, err := q.DeleteQuery("1")
+			return r.SQL, err
+		},
+	}
+	for name, fn := range errs {
+		t.Run(name, func(t *testing.T) {
+			if got, err := fn(); err == nil || got != "" {
+				t.Errorf("QueryBuilder.%s() = %v, %v, want error", name, got, err)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_ListAndCountBy(t *testing.T) {
+	type fields struct {
+		BindType          BindParam
+		SelectDeleted     bool
+		TenantColumn      string
+		OrderByPrimaryKey bool
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		by        []string
+		wantList  string
+		wantCount string
+	}{
+		{"ok", fields{DOLLAR, false, "", false}, []string{"email"},
+			"SELECT id, email, deleted_at FROM users WHERE email = $1 AND deleted_at IS NULL LIMIT $2 OFFSET $3",
+			"SELECT COUNT(*) FROM users WHERE email = $1 AND deleted_at IS NULL"},
+		{"ok extra names", fields{DOLLAR, true, "", true}, []string{"email", "id"},
+			"SELECT id, email, deleted_at FROM users WHERE email = $1 AND id = $2 ORDER BY id LIMIT $3 OFFSET $4",
+			"SELECT COUNT(*) FROM users WHERE email = $1 AND id = $2"},
+		{"ok tenant", fields{DOLLAR, false, "org_id", false}, []string{"email"},
+			"SELECT id, email, deleted_at FROM users WHERE email = $1 AND org_id = $2 AND deleted_at IS NULL LIMIT $3 OFFSET $4",
+			"SELECT COUNT(*) FROM users WHERE email = $1 AND org_id = $2 AND deleted_at IS NULL"},
+		{"ok question", fields{QUESTION, false, "", false}, []string{"email"},
+			"SELECT id, email, deleted_at FROM users WHERE email = ? AND deleted_at IS NULL LIMIT ? OFFSET ?",
+			"SELECT COUNT(*) FROM users WHERE email = ? AND deleted_at IS NULL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:             "users",
+				Columns:           []string{"id", "email", "deleted_at"},
+				BindType:          tt.fields.BindType,
+				SelectDeleted:     tt.fields.SelectDeleted,
+				TenantColumn:      tt.fields.TenantColumn,
+				OrderByPrimaryKey: tt.fields.OrderByPrimaryKey,
+			}
+			list, count := q.ListAndCountBy(tt.by[0], tt.by[1:]...)
+			if list != tt.wantList {
+				t.Errorf("QueryBuilder.ListAndCountBy() list = %v, want %v", list, tt.wantList)
+			}
+			if count != tt.wantCount {
+				t.Errorf("QueryBuilder.ListAndCountBy() count = %v, want %v", count, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_Union(t *testing.T) {
+	users := &QueryBuilder{Table: "users", Columns: []string{"id", "name", "deleted_at"}}
+	admins := &QueryBuilder{Table: "admins", Columns: []string{"id", "name", "deleted_at"}, SelectDeleted: true}
+	tenants := &QueryBuilder{Table: "members", Columns: []string{"id", "name", "deleted_at"}, TenantColumn: "org_id"}
+	questions := &QueryBuilder{Table: "guests", Columns: []string{"id", "name", "deleted_at"}, TenantColumn: "org_id", BindType: QUESTION, NumberedBinds: true}
+	tests := []struct {
+		name    string
+		fn      func(*QueryBuilder) (string, error)
+		other   *QueryBuilder
+		want    string
+		wantErr bool
+	}{
+		{"ok union", users.Union, admins, "SELECT id, name, deleted_at FROM users WHERE deleted_at IS NULL UNION SELECT id, name, deleted_at FROM admins", false},
+		{"ok union all", users.UnionAll, users, "SELECT id, name, deleted_at FROM users WHERE deleted_at IS NULL UNION ALL SELECT id, name, deleted_at FROM users WHERE deleted_at IS NULL", false},
+		{"ok intersect", tenants.Intersect, tenants, "SELECT id, name, deleted_at FROM members WHERE org_id = $1 AND deleted_at IS NULL INTERSECT SELECT id, name, deleted_at FROM members WHERE org_id = $2 AND deleted_at IS NULL", false},
+		{"ok except", tenants.Except, questions, "SELECT id, name, deleted_at FROM members WHERE org_id = $1 AND deleted_at IS NULL EXCEPT SELECT id, name, deleted_at FROM guests WHERE org_id = $2 AND deleted_at IS NULL", false},
+		{"ok bind type", questions.Union, users, "SELECT id, name, deleted_at FROM guests WHERE org_id = ?1 AND deleted_at IS NULL UNION SELECT id, name, deleted_at FROM users WHERE deleted_at IS NULL", false},
+		{"fail columns", users.Union, &QueryBuilder{Table: "logs", Columns: []string{"id", "message"}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn(tt.other)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.Union() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.Union() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_SelectSample(t *testing.T) {
+	type fields struct {
+		BindType      BindParam
+		Dialect       SQLDialect
+		SelectDeleted bool
+		TenantColumn  string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    string
+		wantErr bool
+	}{
+		{"ok postgres", fields{DOLLAR, 0, false, ""}, "SELECT id, name, deleted_at FROM users TABLESAMPLE SYSTEM ($1) WHERE deleted_at IS NULL", false},
+		{"ok postgres tenant", fields{DOLLAR, POSTGRES, false, "org_id"}, "SELECT id, name, deleted_at FROM users TABLESAMPLE SYSTEM ($1) WHERE org_id = $2 AND deleted_at IS NULL", false},
+		{"ok postgres select deleted", fields{DOLLAR, 0, true, ""}, "SELECT id, name, deleted_at FROM users TABLESAMPLE SYSTEM ($1)", false},
+		{"ok sqlite", fields{QUESTION, SQLITE, false, ""}, "SELECT id, name, deleted_at FROM users WHERE deleted_at IS NULL ORDER BY RANDOM() LIMIT ?", false},
+		{"ok sqlite numbered tenant", fields{DOLLAR, SQLITE, false, "org_id"}, "SELECT id, name, deleted_at FROM users WHERE org_id = $1 AND deleted_at IS NULL ORDER BY RANDOM() LIMIT $2", false},
+		{"ok mysql", fields{QUESTION, MYSQL, true, ""}, "SELECT id, name, deleted_at FROM users ORDER BY RAND() LIMIT ?", false},
+		{"fail generic", fields{QUESTION, 0, false, ""}, "", true},
+		{"fail sqlserver", fields{QUESTION, SQLSERVER, false, ""}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         "users",
+				Columns:       []string{"id", "name", "deleted_at"},
+				BindType:      tt.fields.BindType,
+				Dialect:       tt.fields.Dialect,
+				SelectDeleted: tt.fields.SelectDeleted,
+				TenantColumn:  tt.fields.TenantColumn,
+			}
+			got, err := q.SelectSample()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.SelectSample() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.SelectSample() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_Exists(t *testing.T) {
+	type fields struct {
+		BindType     BindParam
+		Dialect      SQLDialect
+		TenantColumn string
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		want       string
+		wantExists string
+	}{
+		{"postgres", fields{DOLLAR, 0, ""},
+			"SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL)",
+			"SELECT EXISTS (SELECT 1 FROM users WHERE email = $1 AND org_id = $2 AND deleted_at IS NULL)"},
+		{"postgres tenant", fields{DOLLAR, POSTGRES, "org_id"},
+			"SELECT EXISTS (SELECT 1 FROM users WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL)",
+			"SELECT EXISTS (SELECT 1 FROM users WHERE email = $1 AND org_id = $2 AND org_id = $3 AND deleted_at IS NULL)"},
+		{"sqlite", fields{QUESTION, SQLITE, ""},
+			"SELECT EXISTS (SELECT 1 FROM users WHERE id = ? AND deleted_at IS NULL)",
+			"SELECT EXISTS (SELECT 1 FROM users WHERE email = ? AND org_id = ? AND deleted_at IS NULL)"},
+		{"mysql", fields{QUESTION, MYSQL, ""},
+			"SELECT 1 FROM users WHERE id = ? AND deleted_at IS NULL LIMIT 1",
+			"SELECT 1 FROM users WHERE email = ? AND org_id = ? AND deleted_at IS NULL LIMIT 1"},
+		{"generic", fields{QUESTION, 0, ""},
+			"SELECT 1 FROM users WHERE id = ? AND deleted_at IS NULL LIMIT 1",
+			"SELECT 1 FROM users WHERE email = ? AND org_id = ? AND deleted_at IS NULL LIMIT 1"},
+		{"sqlserver", fields{QUESTION, SQLSERVER, ""},
+			"SELECT TOP 1 1 FROM users WHERE id = ? AND deleted_at IS NULL",
+			"SELECT TOP 1 1 FROM users WHERE email = ? AND org_id = ? AND deleted_at IS NULL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:        "users",
+				Columns:      []string{"id", "org_id", "email", "deleted_at"},
+				BindType:     tt.fields.BindType,
+				Dialect:      tt.fields.Dialect,
+				TenantColumn: tt.fields.TenantColumn,
+			}
+			if got := q.Exists(); got != tt.want {
+				t.Errorf("QueryBuilder.Exists() = %v, want %v", got, tt.want)
+			}
+			if got := q.ExistsBy("email", "org_id"); got != tt.wantExists {
+				t.Errorf("QueryBuilder.ExistsBy() = %v, want %v", got, tt.wantExists)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_CountDistinctBy(t *testing.T) {
+	type fields struct {
+		BindType      BindParam
+		SelectDeleted bool
+		TenantColumn  string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		by     []string
+		want   string
+	}{
+		{"ok", fields{DOLLAR, false, ""}, []string{"org_id"}, "SELECT COUNT(DISTINCT email) FROM users WHERE org_id = $1 AND deleted_at IS NULL"},
+		{"ok extra names", fields{DOLLAR, true, ""}, []string{"org_id", "active"}, "SELECT COUNT(DISTINCT email) FROM users WHERE org_id = $1 AND active = $2"},
+		{"ok tenant", fields{DOLLAR, false, "org_id"}, []string{"active"}, "SELECT COUNT(DISTINCT email) FROM users WHERE active = $1 AND org_id = $2 AND deleted_at IS NULL"},
+		{"ok question", fields{QUESTION, false, ""}, []string{"org_id"}, "SELECT COUNT(DISTINCT email) FROM users WHERE org_id = ? AND deleted_at IS NULL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         "users",
+				Columns:       []string{"id", "org_id", "email", "active", "deleted_at"},
+				BindType:      tt.fields.BindType,
+				SelectDeleted: tt.fields.SelectDeleted,
+				TenantColumn:  tt.fields.TenantColumn,
+			}
+			if got := q.CountDistinctBy("email", tt.by[0], tt.by[1:]...); got != tt.want {
+				t.Errorf("QueryBuilder.CountDistinctBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_CountEstimate(t *testing.T) {
+	type fields struct {
+		BindType BindParam
+		Dialect  SQLDialect
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    string
+		wantErr bool
+	}{
+		{"ok", fields{DOLLAR, 0}, "SELECT reltuples::bigint FROM pg_class WHERE relname = $1", false},
+		{"ok postgres", fields{DOLLAR, POSTGRES}, "SELECT reltuples::bigint FROM pg_class WHERE relname = $1", false},
+		{"fail mysql", fields{QUESTION, MYSQL}, "", true},
+		{"fail sqlite", fields{QUESTION, SQLITE}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:    "users",
+				Columns:  []string{"id", "name"},
+				BindType: tt.fields.BindType,
+				Dialect:  tt.fields.Dialect,
+			}
+			got, err := q.CountEstimate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.CountEstimate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.CountEstimate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_OrderBy(t *testing.T) {
+	tests := []struct {
+		name    string
+		cols    []string
+		want    string
+		wantErr bool
+	}{
+		{"ok", []string{"name"}, "SELECT id, name, deleted_at FROM users WHERE deleted_at IS NULL ORDER BY name", false},
+		{"ok direction", []string{"name DESC", "id asc"}, "SELECT id, name, deleted_at FROM users WHERE deleted_at IS NULL ORDER BY name DESC, id asc", false},
+		{"fail empty", nil, "", true},
+		{"fail column", []string{"email"}, "", true},
+		{"fail expression", []string{"LENGTH(name)"}, "", true},
+		{"fail direction", []string{"name; DROP TABLE users"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:   "users",
+				Columns: []string{"id", "name", "deleted_at"},
+			}
+			got, err := q.OrderBy(tt.cols...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.OrderBy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.OrderBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_OrderByRaw(t *testing.T) {
+	q := &QueryBuilder{
+		Table:        "users",
+		Columns:      []string{"id", "name", "deleted_at"},
+		TenantColumn: "org_id",
+	}
+	want := "SELECT id, name, deleted_at FROM users WHERE org_id = $1 AND deleted_at IS NULL ORDER BY LENGTH(name)"
+	if got := q.OrderByRaw("LENGTH(name)"); got != want {
+		t.Errorf("QueryBuilder.OrderByRaw() = %v, want %v", got, want)
+	}
+}
+
+func TestQueryBuilder_SelectTopWithTies(t *testing.T) {
+	type fields struct {
+		BindType     BindParam
+		Dialect      SQLDialect
+		TenantColumn string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		n       int
+		orderBy []OrderColumn
+		want    string
+		wantErr bool
+	}{
+		{"ok", fields{DOLLAR, 0, ""}, 3, []OrderColumn{{Name: "score", Desc: true}}, "SELECT id, name, score, deleted_at FROM users WHERE deleted_at IS NULL ORDER BY score DESC FETCH FIRST 3 ROWS WITH TIES", false},
+		{"ok multiple", fields{DOLLAR, POSTGRES, "org_id"}, 10, []OrderColumn{{Name: "score", Desc: true}, {Name: "name"}}, "SELECT id, name, score, deleted_at FROM users WHERE org_id = $1 AND deleted_at IS NULL ORDER BY score DESC, name FETCH FIRST 10 ROWS WITH TIES", false},
+		{"ok sqlserver", fields{DOLLAR, SQLSERVER, ""}, 3, []OrderColumn{{Name: "score", Desc: true}}, "SELECT TOP (3) WITH TIES id, name, score, deleted_at FROM users WHERE deleted_at IS NULL ORDER BY score DESC", false},
+		{"fail n", fields{DOLLAR, 0, ""}, 0, []OrderColumn{{Name: "score"}}, "", true},
+		{"fail empty", fields{DOLLAR, 0, ""}, 3, nil, "", true},
+		{"fail column", fields{DOLLAR, 0, ""}, 3, []OrderColumn{{Name: "rank"}}, "", true},
+		{"fail mysql", fields{QUESTION, MYSQL, ""}, 3, []OrderColumn{{Name: "score"}}, "", true},
+		{"fail sqlite", fields{QUESTION, SQLITE, ""}, 3, []OrderColumn{{Name: "score"}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:        "users",
+				Columns:      []string{"id", "name", "score", "deleted_at"},
+				BindType:     tt.fields.BindType,
+				Dialect:      tt.fields.Dialect,
+				TenantColumn: tt.fields.TenantColumn,
+			}
+			got, err := q.SelectTopWithTies(tt.n, tt.orderBy...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.SelectTopWithTies() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.SelectTopWithTies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_SelectWhereNotExists(t *testing.T) {
+	type fields struct {
+		SelectDeleted bool
+		TenantColumn  string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+	}{
+		{"ok", fields{false, ""}, "SELECT id, name, deleted_at FROM users WHERE NOT EXISTS (SELECT 1 FROM orders WHERE orders.user_id = users.id) AND deleted_at IS NULL"},
+		{"ok select deleted", fields{true, ""}, "SELECT id, name, deleted_at FROM users WHERE NOT EXISTS (SELECT 1 FROM orders WHERE orders.user_id = users.id)"},
+		{"ok tenant", fields{false, "org_id"}, "SELECT id, name, deleted_at FROM users WHERE NOT EXISTS (SELECT 1 FROM orders WHERE orders.user_id = users.id) AND org_id = $1 AND deleted_at IS NULL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:         "users",
+				Columns:       []string{"id", "name", "deleted_at"},
+				SelectDeleted: tt.fields.SelectDeleted,
+				TenantColumn:  tt.fields.TenantColumn,
+			}
+			if got := q.SelectWhereNotExists("orders", "user_id", "id"); got != tt.want {
+				t.Errorf("QueryBuilder.SelectWhereNotExists() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type testSoftDeleteBool struct {
+	ID        string `db:"id"`
+	Name      string `db:"name"`
+	IsDeleted bool   `db:"is_deleted"`
+}
+
+func TestQueryBuilder_SoftDeleteBool(t *testing.T) {
+	q := Must(testSoftDeleteBool{}, SoftDeleteBool("is_deleted"))
+	namedDelete, err := q.NamedDeleteWithReturning()
+	if err != nil {
+		t.Fatalf("QueryBuilder.NamedDeleteWithReturning() error = %v", err)
+	}
+	selectWhere, args := q.SelectWhere(q.SoftDeletePredicate())
+	sqlserver := Must(testSoftDeleteBool{}, SoftDeleteBool("is_deleted"), Dialect(SQLSERVER), BindType(QUESTION))
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"Select", q.Select(), "SELECT id, name, is_deleted FROM test_soft_delete_bool WHERE id = $1 AND is_deleted = FALSE"},
+		{"SelectAll", q.SelectAll(), "SELECT id, name, is_deleted FROM test_soft_delete_bool WHERE is_deleted = FALSE"},
+		{"Delete", q.Delete(), "UPDATE test_soft_delete_bool SET is_deleted = TRUE WHERE id = $1"},
+		{"DeleteIfNotDeleted", q.DeleteIfNotDeleted(), "UPDATE test_soft_delete_bool SET is_deleted = TRUE WHERE id = $1 AND is_deleted = FALSE"},
+		{"NamedDeleteWithReturning", namedDelete, "UPDATE test_soft_delete_bool SET is_deleted = TRUE WHERE id = :id RETURNING id, name, is_deleted"},
+		{"Restore", q.Restore(), "UPDATE test_soft_delete_bool SET is_deleted = FALSE WHERE id = $1"},
+		{"SelectWhere", selectWhere, "SELECT id, name, is_deleted FROM test_soft_delete_bool WHERE is_deleted = $1"},
+		{"Select sqlserver", sqlserver.Select(), "SELECT id, name, is_deleted FROM test_soft_delete_bool WHERE id = ? AND is_deleted = 0"},
+		{"Delete sqlserver", sqlserver.Delete(), "UPDATE test_soft_delete_bool SET is_deleted = 1 WHERE id = ?"},
+		{"Restore sqlserver", sqlserver.Restore(), "UPDATE test_soft_delete_bool SET is_deleted = 0 WHERE id = ?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("QueryBuilder.%s() = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+	if !reflect.DeepEqual(args, []any{false}) {
+		t.Errorf("QueryBuilder.SelectWhere() args = %v, want [false]", args)
+	}
+	dq, err := q.DeleteQuery("1")
+	if err != nil {
+		t.Fatalf("QueryBuilder.DeleteQuery() error = %v", err)
+	}
+	if !reflect.DeepEqual(dq.Args, []any{"1"}) {
+		t.Errorf("QueryBuilder.DeleteQuery() args = %v, want [1]", dq.Args)
+	}
+	if err := q.Validate(); err != nil {
+		t.Errorf("QueryBuilder.Validate() error = %v", err)
+	}
+}
+
+func TestQueryBuilder_DeletedAtNow(t *testing.T) {
+	q := Must(testModel{}, DeletedAtNow(true))
+	namedDelete, err := q.NamedDeleteWithReturning()
+	if err != nil {
+		t.Fatalf("QueryBuilder.NamedDeleteWithReturning() error = %v", err)
+	}
+	sqlite := Must(testModel{}, DeletedAtNow(true), Dialect(SQLITE), BindType(QUESTION))
+	sqlserver := Must(testModel{}, DeletedAtNow(true), Dialect(SQLSERVER), BindType(QUESTION))
+	bools := Must(testSoftDeleteBool{}, SoftDeleteBool("is_deleted"), DeletedAtNow(true))
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"Delete", q.Delete(), "UPDATE test_model SET deleted_at = NOW() WHERE id = $1"},
+		{"DeleteIfNotDeleted", q.DeleteIfNotDeleted(), "UPDATE test_model SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL"},
+		{"NamedDeleteWithReturning", namedDelete, "UPDATE test_model SET deleted_at = NOW() WHERE id = :id RETURNING id, created_at, deleted_at"},
+		{"CascadeDelete", q.CascadeDelete("orders", "model_id"), "UPDATE orders SET deleted_at = NOW() WHERE model_id = $1"},
+		{"Delete sqlite", sqlite.Delete(), "UPDATE test_model SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?"},
+		{"Delete sqlserver", sqlserver.Delete(), "UPDATE test_model SET deleted_at = SYSUTCDATETIME() WHERE id = ?"},
+		{"Delete soft delete bool", bools.Delete(), "UPDATE test_soft_delete_bool SET is_deleted = TRUE WHERE id = $1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("QueryBuilder.%s() = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+	if got := q.PrimaryKeyBindPositions()["Delete"]; got != 1 {
+		t.Errorf("QueryBuilder.PrimaryKeyBindPositions() Delete = %d, want 1", got)
+	}
+	dq, err := q.DeleteQuery("1")
+	if err != nil {
+		t.Fatalf("QueryBuilder.DeleteQuery() error = %v", err)
+	}
+	if !reflect.DeepEqual(dq.Args, []any{"1"}) {
+		t.Errorf("QueryBuilder.DeleteQuery() args = %v, want [1]", dq.Args)
+	}
+}
+
+func TestQueryBuilder_Restore(t *testing.T) {
+	q := &QueryBuilder{
+		Table:        "users",
+		Columns:      []string{"id", "name", "deleted_at"},
+		TenantColumn: "org_id",
+	}
+	want := "UPDATE users SET deleted_at = NULL WHERE id = $1 AND org_id = $2"
+	if got := q.Restore(); got != want {
+		t.Errorf("QueryBuilder.Restore() = %v, want %v", got, want)
+	}
+}
+
+func TestQueryBuilder_InsertFromSelect(t *testing.T) {
+	tests := []struct {
+		name      string
+		selectSQL string
+		cols      []string
+		want      string
+	}{
+		{"ok", "SELECT id, name, email FROM users_old", nil, "INSERT INTO users (id, name, email) SELECT id, name, email FROM users_old"},
+		{"ok columns", "SELECT id, name FROM users_old WHERE created_at > $1", []string{"id", "name"}, "INSERT INTO users (id, name) SELECT id, name FROM users_old WHERE created_at > $1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:   "users",
+				Columns: []string{"id", "name", "email"},
+			}
+			if got := q.InsertFromSelect(tt.selectSQL, tt.cols...); got != tt.want {
+				t.Errorf("QueryBuilder.InsertFromSelect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type testGormModel struct {
+	ID        uint   `gorm:"primaryKey"`
+	Email     string `gorm:"column:email_address;uniqueIndex"`
+	UserID    string `gorm:"not null"`
+	Password  string `gorm:"-"`
+	Notes     string `gorm:"-:migration"`
+	Untagged  string
+	CreatedAt string `gorm:"column:created_at" db:"created"`
+}
+
+func TestGormTags(t *testing.T) {
+	q, err := New(testGormModel{}, GormTags(true))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	want := &QueryBuilder{
+		Table:         "test_gorm_model",
+		Columns:       []string{"id", "email_address", "user_id", "notes", "created_at"},
+		SelectDeleted: true,
+		PrimaryKey:    "id",
+		BindType:      DOLLAR,
+		columnTags:    []string{"gorm"},
+	}
+	if !reflect.DeepEqual(q, want) {
+		t.Errorf("New() = %v, want %v", q, want)
+	}
+	values, err := q.PgxNamedArgs(testGormModel{ID: 1, Email: "jane@example.com"})
+	if err != nil {
+		t.Fatalf("QueryBuilder.PgxNamedArgs() error = %v", err)
+	}
+	if values["id"] != uint(1) || values["email_address"] != "jane@example.com" {
+		t.Errorf("QueryBuilder.PgxNamedArgs() = %v", values)
+	}
+}
+
+type testInferModel struct {
+	testEmbedded
+	UserID    string `db:"id,pkey"`
+	FirstName string
+	HTTPProxy string
+	Address2  string
+	Password  string `db:"-"`
+	CreatedAt time.Time
+	internal  string
+}
+
+type testEmbedded struct {
+	OrgID string
+}
+
+func TestInferColumns(t *testing.T) {
+	q, err := New(testInferModel{}, InferColumns(true))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	want := &QueryBuilder{
+		Table:         "test_infer_model",
+		Columns:       []string{"org_id", "id", "first_name", "http_proxy", "address2", "created_at"},
+		SelectDeleted: true,
+		PrimaryKey:    "id",
+		BindType:      DOLLAR,
+		columnTags:    []string{"db"},
+		inferColumns:  true,
+	}
+	if !reflect.DeepEqual(q, want) {
+		t.Errorf("New() = %v, want %v", q, want)
+	}
+	values, err := q.PgxNamedArgs(testInferModel{UserID: "1", FirstName: "Jane"})
+	if err != nil {
+		t.Fatalf("QueryBuilder.PgxNamedArgs() error = %v", err)
+	}
+	if values["id"] != "1" || values["first_name"] != "Jane" || len(values) != 6 {
+		t.Errorf("QueryBuilder.PgxNamedArgs() = %v", values)
+	}
+
+	q, err = New(testInferModel{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if want := []string{"id"}; !reflect.DeepEqual(q.Columns, want) {
+		t.Errorf("New() columns = %v, want %v", q.Columns, want)
+	}
+}
+
+func Test_snakeCase(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"ID", "id"},
+		{"UserID", "user_id"},
+		{"HTTPServer", "http_server"},
+		{"CreatedAt", "created_at"},
+		{"OAuth2Token", "o_auth2_token"},
+		{"APIKey", "api_key"},
+		{"FirstName", "first_name"},
+		{"Address2", "address2"},
+		{"V2Model", "v2_model"},
+		{"SHA256Sum", "sha256_sum"},
+		{"name", "name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snakeCase(tt.name); got != tt.want {
+				t.Errorf("snakeCase() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_ReturningAll(t *testing.T) {
+	type fields struct {
+		Dialect        SQLDialect
+		ReturningStar  bool
+		ReturningOrder []string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    map[string]string
+		wantErr bool
+	}{
+		{"ok", fields{POSTGRES, false, nil}, map[string]string{
+			"InsertReturningAll":       "INSERT INTO users (id, name, deleted_at) VALUES ($1, $2, $3) RETURNING id, name, deleted_at",
+			"UpdateReturningAll":       "UPDATE users SET name = $1, deleted_at = $2 WHERE id = $3 RETURNING id, name, deleted_at",
+			"DeleteWithReturning":      "UPDATE users SET deleted_at = $1 WHERE id = $2 RETURNING id, name, deleted_at",
+			"DeleteReturningAll":       "UPDATE users SET deleted_at = $1 WHERE id = $2 RETURNING id, name, deleted_at",
+			"NamedDeleteWithReturning": "UPDATE users SET deleted_at = :deleted_at WHERE id = :id RETURNING id, name, deleted_at",
 		}, false},
-		{"ok with options", args{testTable{}, []Option{TableTag("table"), ColumnTag("col"), BindType(QUESTION)}}, &QueryBuilder{
-			Table:         "foo",
-			Columns:       []string{"foo_id", "foo_name", "foo_email"},
-			SelectDeleted: false,
-			PrimaryKey:    "foo_id",
-			BindType:      QUESTION,
+		{"ok order", fields{POSTGRES, false, []string{"name", "id"}}, map[string]string{
+			"InsertReturningAll":       "INSERT INTO users (id, name, deleted_at) VALUES ($1, $2, $3) RETURNING name, id",
+			"UpdateReturningAll":       "UPDATE users SET name = $1, deleted_at = $2 WHERE id = $3 RETURNING name, id",
+			"DeleteWithReturning":      "UPDATE users SET deleted_at = $1 WHERE id = $2 RETURNING name, id",
+			"DeleteReturningAll":       "UPDATE users SET deleted_at = $1 WHERE id = $2 RETURNING name, id",
+			"NamedDeleteWithReturning": "UPDATE users SET deleted_at = :deleted_at WHERE id = :id RETURNING name, id",
 		}, false},
-		{"ok with deprecated options", args{testTable{}, []Option{TableTag("table"), WithColumnTag("col")}}, &QueryBuilder{
-			Table:         "foo",
-			Columns:       []string{"foo_id", "foo_name", "foo_email"},
-			SelectDeleted: false,
-			PrimaryKey:    "foo_id",
-			BindType:      DOLLAR,
+		{"ok star", fields{SQLITE, true, []string{"name", "id"}}, map[string]string{
+			"InsertReturningAll":       "INSERT INTO users (id, name, deleted_at) VALUES ($1, $2, $3) RETURNING *",
+			"UpdateReturningAll":       "UPDATE users SET name = $1, deleted_at = $2 WHERE id = $3 RETURNING *",
+			"DeleteWithReturning":      "UPDATE users SET deleted_at = $1 WHERE id = $2 RETURNING *",
+			"DeleteReturningAll":       "UPDATE users SET deleted_at = $1 WHERE id = $2 RETURNING *",
+			"NamedDeleteWithReturning": "UPDATE users SET deleted_at = :deleted_at WHERE id = :id RETURNING *",
 		}, false},
-		{"fail", args{"not a struct", nil}, nil, true},
-		{"fail primary keys", args{badModel{}, nil}, nil, true},
+		{"fail mysql", fields{MYSQL, true, nil}, map[string]string{}, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := New(tt.args.i, tt.args.opts...)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
-				return
+			q := &QueryBuilder{
+				Table:          "users",
+				Columns:        []string{"id", "name", "deleted_at"},
+				Dialect:        tt.fields.Dialect,
+				ReturningStar:  tt.fields.ReturningStar,
+				ReturningOrder: tt.fields.ReturningOrder,
 			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("New() = %v, want %v", got, tt.want)
+			for name, fn := range map[string]func() (string, error){
+				"InsertReturningAll":       q.InsertReturningAll,
+				"UpdateReturningAll":       q.UpdateReturningAll,
+				"DeleteWithReturning":      q.DeleteWithReturning,
+				"DeleteReturningAll":       q.DeleteReturningAll,
+				"NamedDeleteWithReturning": q.NamedDeleteWithReturning,
+			} {
+				got, err := fn()
+				if (err != nil) != tt.wantErr {
+					t.Errorf("QueryBuilder.%s() error = %v, wantErr %v", name, err, tt.wantErr)
+				}
+				if got != tt.want[name] {
+					t.Errorf("QueryBuilder.%s() = %v, want %v", name, got, tt.want[name])
+				}
 			}
+		})
+	}
+}
 
-			defer func() {
-				r := recover()
-				if r != nil != tt.wantErr {
-					t.Errorf("Must() panic = %v, wantErr %v", r, tt.wantErr)
-				}
-			}()
-			gotMust := Must(tt.args.i, tt.args.opts...)
-			if !reflect.DeepEqual(gotMust, tt.want) {
-				t.Errorf("Must() = %v, want %v", gotMust, tt.want)
+func TestQueryBuilder_SelectAfter(t *testing.T) {
+	type fields struct {
+		BindType     BindParam
+		Dialect      SQLDialect
+		TenantColumn string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		sortCol string
+		want    string
+		wantErr bool
+	}{
+		{"ok", fields{DOLLAR, 0, ""}, "", "SELECT id, name, created_at, deleted_at FROM users WHERE (id) > ($1) AND deleted_at IS NULL ORDER BY id LIMIT $2", false},
+		{"ok primary key", fields{DOLLAR, 0, ""}, "id", "SELECT id, name, created_at, deleted_at FROM users WHERE (id) > ($1) AND deleted_at IS NULL ORDER BY id LIMIT $2", false},
+		{"ok sort column", fields{DOLLAR, 0, ""}, "created_at", "SELECT id, name, created_at, deleted_at FROM users WHERE (created_at, id) > ($1, $2) AND deleted_at IS NULL ORDER BY created_at, id LIMIT $3", false},
+		{"ok tenant", fields{DOLLAR, 0, "org_id"}, "created_at", "SELECT id, name, created_at, deleted_at FROM users WHERE (created_at, id) > ($1, $2) AND org_id = $3 AND deleted_at IS NULL ORDER BY created_at, id LIMIT $4", false},
+		{"ok mysql", fields{QUESTION, MYSQL, ""}, "name", "SELECT id, name, created_at, deleted_at FROM users WHERE (name, id) > (?, ?) AND deleted_at IS NULL ORDER BY name, id LIMIT ?", false},
+		{"fail column", fields{DOLLAR, 0, ""}, "email", "", true},
+		{"fail sqlserver", fields{QUESTION, SQLSERVER, ""}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:        "users",
+				Columns:      []string{"id", "name", "created_at", "deleted_at"},
+				BindType:     tt.fields.BindType,
+				Dialect:      tt.fields.Dialect,
+				TenantColumn: tt.fields.TenantColumn,
+			}
+			got, err := q.SelectAfter(tt.sortCol)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.SelectAfter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.SelectAfter() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestNewQueryBuilder(t *testing.T) {
-	type args struct {
-		table   string
-		columns []string
+func TestQueryBuilder_InsertReturning(t *testing.T) {
+	type fields struct {
+		BindType BindParam
+		Dialect  SQLDialect
 	}
 	tests := []struct {
-		name string
-		args args
-		want *QueryBuilder
+		name    string
+		fields  fields
+		cols    []string
+		want    string
+		wantErr bool
 	}{
-		{"ok", args{"users", []string{"id", "name", "email"}}, &QueryBuilder{
-			Table:         "users",
-			Columns:       []string{"id", "name", "email"},
-			SelectDeleted: false,
-			PrimaryKey:    "id",
-			BindType:      DOLLAR,
-		}},
+		{"ok", fields{DOLLAR, 0}, []string{"created_at"}, "INSERT INTO users (id, name, created_at) VALUES ($1, $2, $3) RETURNING created_at", false},
+		{"ok many", fields{QUESTION, SQLITE}, []string{"id", "created_at"}, "INSERT INTO users (id, name, created_at) VALUES (?, ?, ?) RETURNING id, created_at", false},
+		{"fail empty", fields{DOLLAR, 0}, nil, "", true},
+		{"fail column", fields{DOLLAR, 0}, []string{"updated_at"}, "", true},
+		{"fail mysql", fields{QUESTION, MYSQL}, []string{"created_at"}, "", true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewQueryBuilder(tt.args.table, tt.args.columns); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("NewQueryBuilder() = %v, want %v", got, tt.want)
+			q := &QueryBuilder{
+				Table:    "users",
+				Columns:  []string{"id", "name", "created_at"},
+				BindType: tt.fields.BindType,
+				Dialect:  tt.fields.Dialect,
+			}
+			got, err := q.InsertReturning(tt.cols...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.InsertReturning() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.InsertReturning() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestQueryBuilder_Queries(t *testing.T) {
+func TestQueryBuilder_SelectWhereInSubquery(t *testing.T) {
 	type fields struct {
-		Table         string
-		Columns       []string
 		SelectDeleted bool
-		PrimaryKey    string
-		BindType      BindParam
+		TenantColumn  string
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		want   string
-		want1  string
-		want2  string
-		want3  string
+		name     string
+		fields   fields
+		subquery string
+		want     string
 	}{
-		{"selectDeleted", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, true, "", 0},
-			"SELECT id, name, email, created_at, deleted_at FROM users WHERE id = $1",
-			"INSERT INTO users (id, name, email, created_at, deleted_at) VALUES ($1, $2, $3, $4, $5)",
-			"UPDATE users SET name = $1, email = $2, deleted_at = $3 WHERE id = $4",
-			"UPDATE users SET deleted_at = $1 WHERE id = $2"},
-		{"selectWithCustomId", fields{"users", []string{"oid", "name", "email", "created_at", "deleted_at"}, true, "oid", DOLLAR},
-			"SELECT oid, name, email, created_at, deleted_at FROM users WHERE oid = $1",
-			"INSERT INTO users (oid, name, email, created_at, deleted_at) VALUES ($1, $2, $3, $4, $5)",
-			"UPDATE users SET name = $1, email = $2, deleted_at = $3 WHERE oid = $4",
-			"UPDATE users SET deleted_at = $1 WHERE oid = $2"},
-		{"noSelectDeleted", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, false, "id", QUESTION},
-			"SELECT id, name, email, created_at, deleted_at FROM users WHERE id = ? AND deleted_at IS NULL",
-			"INSERT INTO users (id, name, email, created_at, deleted_at) VALUES (?, ?, ?, ?, ?)",
-			"UPDATE users SET name = ?, email = ?, deleted_at = ? WHERE id = ?",
-			"UPDATE users SET deleted_at = ? WHERE id = ?"},
+		{"ok", fields{false, ""}, "SELECT user_id FROM orders WHERE created_at > $1", "SELECT id, name, deleted_at FROM users WHERE id IN (SELECT user_id FROM orders WHERE created_at > $1) AND deleted_at IS NULL"},
+		{"ok select deleted", fields{true, ""}, "SELECT user_id FROM orders", "SELECT id, name, deleted_at FROM users WHERE id IN (SELECT user_id FROM orders)"},
+		{"ok tenant", fields{false, "org_id"}, "SELECT user_id FROM orders WHERE created_at > $2", "SELECT id, name, deleted_at FROM users WHERE id IN (SELECT user_id FROM orders WHERE created_at > $2) AND org_id = $1 AND deleted_at IS NULL"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			q := &QueryBuilder{
-				Table:         tt.fields.Table,
-				Columns:       tt.fields.Columns,
+				Table:         "users",
+				Columns:       []string{"id", "name", "deleted_at"},
 				SelectDeleted: tt.fields.SelectDeleted,
-				PrimaryKey:    tt.fields.PrimaryKey,
-				BindType:      tt.fields.BindType,
+				TenantColumn:  tt.fields.TenantColumn,
 			}
-			got, got1, got2, got3 := q.Queries()
-			if got != tt.want {
-				t.Errorf("QueryBuilder.Queries() got = %v, want %v", got, tt.want)
+			if got := q.SelectWhereInSubquery("id", tt.subquery); got != tt.want {
+				t.Errorf("QueryBuilder.SelectWhereInSubquery() = %v, want %v", got, tt.want)
 			}
-			if got1 != tt.want1 {
-				t.Errorf("QueryBuilder.Queries() got1 = %v, want %v", got1, tt.want1)
+		})
+	}
+}
+
+func TestQueryBuilder_ScanColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []string
+		prefix  string
+		want    string
+	}{
+		{"ok", []string{"id", "name"}, "u", "users.id AS u_id, users.name AS u_name"},
+		{"ok empty", []string{"id", "name"}, "", "users.id AS users_id, users.name AS users_name"},
+		{"ok qualified", []string{"id", "data.email"}, "u", "users.id AS u_id, data.email AS u_data_email"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:   "users",
+				Columns: tt.columns,
 			}
-			if got2 != tt.want2 {
-				t.Errorf("QueryBuilder.Queries() got2 = %v, want %v", got2, tt.want2)
+			if got := q.ScanColumns(tt.prefix); got != tt.want {
+				t.Errorf("QueryBuilder.ScanColumns() = %v, want %v", got, tt.want)
 			}
-			if got3 != tt.want3 {
-				t.Errorf("QueryBuilder.Queries() got3 = %v, want %v", got3, tt.want3)
+		})
+	}
+}
+
+func TestQueryBuilder_ColumnIndex(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []string
+		want    map[string]int
+	}{
+		{"ok", []string{"id", "name", "created_at"}, map[string]int{"id": 0, "name": 1, "created_at": 2}},
+		{"ok empty", nil, map[string]int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueryBuilder{
+				Table:   "users",
+				Columns: tt.columns,
+			}
+			if got := q.ColumnIndex(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryBuilder.ColumnIndex() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestQueryBuilder_SelectBy(t *testing.T) {
+func TestQueryBuilder_InsertReturningColumn(t *testing.T) {
 	type fields struct {
-		Table         string
-		Columns       []string
-		SelectDeleted bool
-	}
-	type args struct {
-		name       string
-		extraNames []string
+		BindType BindParam
+		Dialect  SQLDialect
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   string
+		name    string
+		fields  fields
+		col     string
+		want    string
+		wantErr bool
 	}{
-		{"selectDeleted", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, true}, args{"email", nil}, "SELECT id, name, email, created_at, deleted_at FROM users WHERE email = $1"},
-		{"noSelectDeleted", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, false}, args{"email", nil}, "SELECT id, name, email, created_at, deleted_at FROM users WHERE email = $1 AND deleted_at IS NULL"},
-		{"extra names", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, false}, args{"name", []string{"email"}}, "SELECT id, name, email, created_at, deleted_at FROM users WHERE name = $1 AND email = $2 AND deleted_at IS NULL"},
+		{"ok", fields{DOLLAR, 0}, "sort_order", "INSERT INTO users (name, sort_order) VALUES ($1, $2) RETURNING sort_order", false},
+		{"ok sqlite", fields{QUESTION, SQLITE}, "id", "INSERT INTO users (name, sort_order) VALUES (?, ?) RETURNING id", false},
+		{"fail column", fields{DOLLAR, 0}, "created_at", "", true},
+		{"fail mysql", fields{QUESTION, MYSQL}, "sort_order", "", true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			q := &QueryBuilder{
-				Table:         tt.fields.Table,
-				Columns:       tt.fields.Columns,
-				SelectDeleted: tt.fields.SelectDeleted,
+				Table:    "users",
+				Columns:  []string{"id", "name", "sort_order"},
+				BindType: tt.fields.BindType,
+				Dialect:  tt.fields.Dialect,
 			}
-			if got := q.SelectBy(tt.args.name, tt.args.extraNames...); got != tt.want {
-				t.Errorf("QueryBuilder.SelectBy() = %v, want %v", got, tt.want)
+			got, err := q.InsertReturningColumn(tt.col)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QueryBuilder.InsertReturningColumn() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("QueryBuilder.InsertReturningColumn() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestQueryBuilder_SelectAll(t *testing.T) {
+func TestQueryBuilder_CascadeDelete(t *testing.T) {
 	type fields struct {
-		Table         string
-		Columns       []string
-		SelectDeleted bool
+		BindType         BindParam
+		Dialect          SQLDialect
+		SoftDeleteColumn string
+		SoftDeleteBool   bool
 	}
 	tests := []struct {
 		name   string
 		fields fields
 		want   string
 	}{
-		{"all", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, true}, "SELECT id, name, email, created_at, deleted_at FROM users"},
-		{"non deleted", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, false}, "SELECT id, name, email, created_at, deleted_at FROM users WHERE deleted_at IS NULL"},
+		{"ok", fields{DOLLAR, 0, "", false}, "UPDATE orders SET deleted_at = $1 WHERE user_id = $2"},
+		{"ok question", fields{QUESTION, 0, "", false}, "UPDATE orders SET deleted_at = ? WHERE user_id = ?"},
+		{"ok deleted at column", fields{DOLLAR, 0, "removed_at", false}, "UPDATE orders SET removed_at = $1 WHERE user_id = $2"},
+		{"ok soft delete bool", fields{DOLLAR, 0, "is_deleted", true}, "UPDATE orders SET is_deleted = TRUE WHERE user_id = $1"},
+		{"ok soft delete bool sqlserver", fields{QUESTION, SQLSERVER, "is_deleted", true}, "UPDATE orders SET is_deleted = 1 WHERE user_id = ?"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			q := &QueryBuilder{
-				Table:         tt.fields.Table,
-				Columns:       tt.fields.Columns,
-				SelectDeleted: tt.fields.SelectDeleted,
+				Table:            "users",
+				Columns:          []string{"id", "name", "deleted_at"},
+				BindType:         tt.fields.BindType,
+				Dialect:          tt.fields.Dialect,
+				SoftDeleteColumn: tt.fields.SoftDeleteColumn,
+				SoftDeleteBool:   tt.fields.SoftDeleteBool,
 			}
-			if got := q.SelectAll(); got != tt.want {
-				t.Errorf("QueryBuilder.SelectAll() = %v, want %v", got, tt.want)
+			if got := q.CascadeDelete("orders", "user_id"); got != tt.want {
+				t.Errorf("QueryBuilder.CascadeDelete() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestQueryBuilder_InsertWithReturning(t *testing.T) {
+func TestQueryBuilder_PurgeBefore(t *testing.T) {
 	type fields struct {
-		Table         string
-		Columns       []string
-		SelectDeleted bool
+		BindType         BindParam
+		TenantColumn     string
+		SoftDeleteColumn string
+		SoftDeleteBool   bool
 	}
 	tests := []struct {
 		name   string
 		fields fields
 		want   string
 	}{
-		{"ok", fields{"users", []string{"id", "name", "email"}, false}, "INSERT INTO users (name, email) VALUES ($1, $2) RETURNING id"},
-		{"ok no id", fields{"users", []string{"name", "email"}, false}, "INSERT INTO users (name, email) VALUES ($1, $2) RETURNING id"},
+		{"ok", fields{DOLLAR, "", "", false}, "DELETE FROM users WHERE deleted_at IS NOT NULL AND deleted_at < $1"},
+		{"ok tenant", fields{DOLLAR, "org_id", "", false}, "DELETE FROM users WHERE deleted_at IS NOT NULL AND deleted_at < $1 AND org_id = $2"},
+		{"ok question", fields{QUESTION, "", "", false}, "DELETE FROM users WHERE deleted_at IS NOT NULL AND deleted_at < ?"},
+		{"ok soft delete bool", fields{DOLLAR, "org_id", "is_deleted", true}, "DELETE FROM users WHERE is_deleted = TRUE AND org_id = $1"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			q := &QueryBuilder{
-				Table:         tt.fields.Table,
-				Columns:       tt.fields.Columns,
-				SelectDeleted: tt.fields.SelectDeleted,
+				Table:            "users",
+				Columns:          []string{"id", "name", "deleted_at", "is_deleted"},
+				BindType:         tt.fields.BindType,
+				TenantColumn:     tt.fields.TenantColumn,
+				SoftDeleteColumn: tt.fields.SoftDeleteColumn,
+				SoftDeleteBool:   tt.fields.SoftDeleteBool,
 			}
-			if got := q.InsertWithReturning(); got != tt.want {
-				t.Errorf("QueryBuilder.InsertWithReturning() = %v, want %v", got, tt.want)
+			if got := q.PurgeBefore(); got != tt.want {
+				t.Errorf("QueryBuilder.PurgeBefore() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestQueryBuilder_NamedInsert(t *testing.T) {
+func TestQueryBuilder_PlaceholderMap(t *testing.T) {
 	type fields struct {
-		Table         string
-		Columns       []string
-		SelectDeleted bool
+		BindType      BindParam
+		NumberedBinds bool
+		Casts         map[string]string
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		want   string
+		name      string
+		fields    fields
+		startBind int
+		want      map[string]string
 	}{
-		{"ok", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, false}, "INSERT INTO users (id, name, email, created_at, deleted_at) VALUES (:id, :name, :email, :created_at, :deleted_at)"},
+		{"ok", fields{DOLLAR, false, nil}, 1, map[string]string{"id": "$1", "name": "$2", "status": "$3"}},
+		{"ok start", fields{DOLLAR, false, nil}, 3, map[string]string{"id": "$3", "name": "$4", "status": "$5"}},
+		{"ok casts", fields{DOLLAR, false, map[string]string{"status": "status_enum"}}, 1, map[string]string{"id": "$1", "name": "$2", "status": "$3::status_enum"}},
+		{"ok question", fields{QUESTION, false, nil}, 2, map[string]string{"id": "?", "name": "?", "status": "?"}},
+		{"ok numbered", fields{QUESTION, true, nil}, 2, map[string]string{"id": "?2", "name": "?3", "status": "?4"}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			q := &QueryBuilder{
-				Table:         tt.fields.Table,
-				Columns:       tt.fields.Columns,
-				SelectDeleted: tt.fields.SelectDeleted,
+				Table:         "users",
+				Columns:       []string{"id", "name", "status"},
+				BindType:      tt.fields.BindType,
+				NumberedBinds: tt.fields.NumberedBinds,
+				Casts:         tt.fields.Casts,
 			}
-			if got := q.NamedInsert(); got != tt.want {
-				t.Errorf("QueryBuilder.NamedInsert() = %v, want %v", got, tt.want)
+			if got := q.PlaceholderMap(tt.startBind); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryBuilder.PlaceholderMap() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestQueryBuilder_NamedInsertWithReturning(t *testing.T) {
+func TestQueryBuilder_OptimisticLock(t *testing.T) {
 	type fields struct {
-		Table         string
-		Columns       []string
-		SelectDeleted bool
+		Columns      []string
+		TenantColumn string
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		want   string
+		name      string
+		fields    fields
+		want      string
+		wantNamed string
 	}{
-		{"ok", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, false}, "INSERT INTO users (name, email, created_at, deleted_at) VALUES (:name, :email, :created_at, :deleted_at) RETURNING id"},
+		{"ok", fields{[]string{"id", "name", "version"}, ""},
+			"UPDATE users SET name = $1, version = version + 1 WHERE id = $2 AND version = $3",
+			"UPDATE users SET name = :name, version = version + 1 WHERE id = :id AND version = :version"},
+		{"ok tenant", fields{[]string{"id", "org_id", "name", "version", "created_at"}, "org_id"},
+			"UPDATE users SET org_id = $1, name = $2, version = version + 1 WHERE id = $3 AND version = $4 AND org_id = $5",
+			"UPDATE users SET org_id = :org_id, name = :name, version = version + 1 WHERE id = :id AND version = :version AND org_id = :org_id"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			q := &QueryBuilder{
-				Table:         tt.fields.Table,
+				Table:         "users",
 				Columns:       tt.fields.Columns,
-				SelectDeleted: tt.fields.SelectDeleted,
+				TenantColumn:  tt.fields.TenantColumn,
+				VersionColumn: "version",
 			}
-			if got := q.NamedInsertWithReturning(); got != tt.want {
-				t.Errorf("QueryBuilder.NamedInsertWithReturning() = %v, want %v", got, tt.want)
+			if got := q.Update(); got != tt.want {
+				t.Errorf("QueryBuilder.Update() = %v, want %v", got, tt.want)
+			}
+			if got := q.NamedUpdate(); got != tt.wantNamed {
+				t.Errorf("QueryBuilder.NamedUpdate() = %v, want %v", got, tt.wantNamed)
 			}
 		})
 	}
 }
 
-func TestQueryBuilder_NamedUpdate(t *testing.T) {
-	type fields struct {
-		Table         string
-		Columns       []string
-		SelectDeleted bool
+func TestQueryBuilder_canonicalOrder(t *testing.T) {
+	q := &QueryBuilder{
+		Table:         "users",
+		Columns:       []string{"id", "org_id", "name", "version", "created_at", "deleted_at"},
+		TenantColumn:  "org_id",
+		VersionColumn: "version",
 	}
+	selectAfter, _ := q.SelectAfter("created_at")
+	selectAsOf, _ := q.SelectAsOf()
+	selectWhere, _ := q.SelectWhere(And(Eq("name", "jane"), Gt("created_at", 1)))
+	list, count := q.ListAndCountBy("name", "version")
 	tests := []struct {
-		name   string
-		fields fields
-		want   string
+		name string
+		got  string
+		want string
 	}{
-		{"ok", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, false}, "UPDATE users SET name = :name, email = :email, deleted_at = :deleted_at WHERE id = :id"},
+		{"Select", q.Select(), "SELECT id, org_id, name, version, created_at, deleted_at FROM users WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL"},
+		{"SelectBy", q.SelectBy("name", "version"), "SELECT id, org_id, name, version, created_at, deleted_at FROM users WHERE name = $1 AND version = $2 AND org_id = $3 AND deleted_at IS NULL"},
+		{"SelectByAny", q.SelectByAny("name", "version"), "SELECT id, org_id, name, version, created_at, deleted_at FROM users WHERE (name = $1 OR version = $2) AND org_id = $3 AND deleted_at IS NULL"},
+		{"SelectIn", q.SelectIn("id", 2), "SELECT id, org_id, name, version, created_at, deleted_at FROM users WHERE id IN ($1, $2) AND org_id = $3 AND deleted_at IS NULL"},
+		{"SelectAll", q.SelectAll(), "SELECT id, org_id, name, version, created_at, deleted_at FROM users WHERE org_id = $1 AND deleted_at IS NULL"},
+		{"SelectAfter", selectAfter, "SELECT id, org_id, name, version, created_at, deleted_at FROM users WHERE (created_at, id) > ($1, $2) AND org_id = $3 AND deleted_at IS NULL ORDER BY created_at, id LIMIT $4"},
+		{"SelectAsOf", selectAsOf, "SELECT id, org_id, name, version, created_at, deleted_at FROM users FOR SYSTEM_TIME AS OF $1 WHERE id = $2 AND org_id = $3 AND deleted_at IS NULL"},
+		{"SelectWhere", selectWhere, "SELECT id, org_id, name, version, created_at, deleted_at FROM users WHERE (name = $1 AND created_at > $2) AND org_id = $3"},
+		{"ListAndCountBy list", list, "SELECT id, org_id, name, version, created_at, deleted_at FROM users WHERE name = $1 AND version = $2 AND org_id = $3 AND deleted_at IS NULL LIMIT $4 OFFSET $5"},
+		{"ListAndCountBy count", count, "SELECT COUNT(*) FROM users WHERE name = $1 AND version = $2 AND org_id = $3 AND deleted_at IS NULL"},
+		{"Update", q.Update(), "UPDATE users SET org_id = $1, name = $2, deleted_at = $3, version = version + 1 WHERE id = $4 AND version = $5 AND org_id = $6"},
+		{"Delete", q.Delete(), "UPDATE users SET deleted_at = $1 WHERE id = $2 AND org_id = $3"},
+		{"DeleteIfNotDeleted", q.DeleteIfNotDeleted(), "UPDATE users SET deleted_at = $1 WHERE id = $2 AND org_id = $3 AND deleted_at IS NULL"},
+		{"Restore", q.Restore(), "UPDATE users SET deleted_at = NULL WHERE id = $1 AND org_id = $2"},
+		{"HardDelete", q.HardDelete(), "DELETE FROM users WHERE id = $1 AND org_id = $2"},
+		{"PurgeBefore", q.PurgeBefore(), "DELETE FROM users WHERE deleted_at IS NOT NULL AND deleted_at < $1 AND org_id = $2"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			q := &QueryBuilder{
-				Table:         tt.fields.Table,
-				Columns:       tt.fields.Columns,
-				SelectDeleted: tt.fields.SelectDeleted,
+			if tt.got != tt.want {
+				t.Errorf("QueryBuilder.%s() = %v, want %v", tt.name, tt.got, tt.want)
 			}
-			if got := q.NamedUpdate(); got != tt.want {
-				t.Errorf("QueryBuilder.NamedUpdate() = %v, want %v", got, tt.want)
+			// The binding parameters must be numbered in order without gaps.
+			n := 0
+			for _, s := range strings.Split(tt.got, "$")[1:] {
+				n++
+				if !strings.HasPrefix(s, strconv.Itoa(n)) {
+					t.Errorf("QueryBuilder.%s() parameter $%s, want $%d", tt.name, s, n)
+					break
+				}
 			}
 		})
 	}
 }
 
-func TestQueryBuilder_HardDelete(t *testing.T) {
+func TestQueryBuilder_deletedAtColumn(t *testing.T) {
+	q := &QueryBuilder{
+		Table:            "users",
+		Columns:          []string{"id", "name", "created_at", "removed_at"},
+		SoftDeleteColumn: "removed_at",
+	}
+	deleteWithReturning, _ := q.DeleteWithReturning()
+	namedDeleteWithReturning, _ := q.NamedDeleteWithReturning()
+	list, count := q.ListAndCountBy("name")
+	selectWhere, _ := q.SelectWhere(q.SoftDeletePredicate())
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"Select", q.Select(), "SELECT id, name, created_at, removed_at FROM users WHERE id = $1 AND removed_at IS NULL"},
+		{"SelectBy", q.SelectBy("name"), "SELECT id, name, created_at, removed_at FROM users WHERE name = $1 AND removed_at IS NULL"},
+		{"SelectAll", q.SelectAll(), "SELECT id, name, created_at, removed_at FROM users WHERE removed_at IS NULL"},
+		{"SelectIn", q.SelectIn("id", 2), "SELECT id, name, created_at, removed_at FROM users WHERE id IN ($1, $2) AND removed_at IS NULL"},
+		{"ListAndCountBy list", list, "SELECT id, name, created_at, removed_at FROM users WHERE name = $1 AND removed_at IS NULL LIMIT $2 OFFSET $3"},
+		{"ListAndCountBy count", count, "SELECT COUNT(*) FROM users WHERE name = $1 AND removed_at IS NULL"},
+		{"CountDistinctBy", q.CountDistinctBy("id", "name"), "SELECT COUNT(DISTINCT id) FROM users WHERE name = $1 AND removed_at IS NULL"},
+		{"Delete", q.Delete(), "UPDATE users SET removed_at = $1 WHERE id = $2"},
+		{"DeleteIfNotDeleted", q.DeleteIfNotDeleted(), "UPDATE users SET removed_at = $1 WHERE id = $2 AND removed_at IS NULL"},
+		{"DeleteWithReturning", deleteWithReturning, "UPDATE users SET removed_at = $1 WHERE id = $2 RETURNING id, name, created_at, removed_at"},
+		{"NamedDeleteWithReturning", namedDeleteWithReturning, "UPDATE users SET removed_at = :removed_at WHERE id = :id RETURNING id, name, created_at, removed_at"},
+		{"Restore", q.Restore(), "UPDATE users SET removed_at = NULL WHERE id = $1"},
+		{"PurgeBefore", q.PurgeBefore(), "DELETE FROM users WHERE removed_at IS NOT NULL AND removed_at < $1"},
+		{"SelectWhere", selectWhere, "SELECT id, name, created_at, removed_at FROM users WHERE removed_at IS NULL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("QueryBuilder.%s() = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBuilder_softDeletePredicate(t *testing.T) {
 	type fields struct {
-		Table         string
-		Columns       []string
-		SelectDeleted bool
+		Dialect          SQLDialect
+		SoftDeleteColumn string
+		ShortIsNull      bool
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		want   string
+		name      string
+		fields    fields
+		predicate string
 	}{
-		{"ok", fields{"users", []string{"id", "name", "email", "created_at", "deleted_at"}, false}, "DELETE FROM users WHERE id = $1"},
+		{"ok", fields{0, "", false}, "deleted_at IS NULL"},
+		{"ok column", fields{0, "removed_at", false}, "removed_at IS NULL"},
+		{"ok short", fields{POSTGRES, "", true}, "deleted_at ISNULL"},
+		{"ok short sqlite", fields{SQLITE, "removed_at", true}, "removed_at ISNULL"},
+		{"ok short mysql", fields{MYSQL, "", true}, "deleted_at IS NULL"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			q := &QueryBuilder{
-				Table:         tt.fields.Table,
-				Columns:       tt.fields.Columns,
-				SelectDeleted: tt.fields.SelectDeleted,
+				Table:            "users",
+				Columns:          []string{"id", "name", "deleted_at", "removed_at"},
+				Dialect:          tt.fields.Dialect,
+				SoftDeleteColumn: tt.fields.SoftDeleteColumn,
+				ShortIsNull:      tt.fields.ShortIsNull,
 			}
-			if got := q.HardDelete(); got != tt.want {
-				t.Errorf("QueryBuilder.HardDelete() = %v, want %v", got, tt.want)
+			selectWhere, _ := q.SelectWhere(q.SoftDeletePredicate())
+			// The predicate must be exactly the same in all the queries to
+			// match the predicate of partial indexes.
+			for _, s := range []string{q.Select(), q.SelectAll(), q.SelectBy("name"), q.DeleteIfNotDeleted(), selectWhere} {
+				if !strings.HasSuffix(s, " "+tt.predicate) {
+					t.Errorf("query %q does not end with %q", s, tt.predicate)
+				}
 			}
 		})
 	}
@@ -0,0 +1,28 @@
+package qb
+
+import "sync"
+
+// queryLog is the list of queries generated by a query builder with the
+// RecordQueries option. It is safe for concurrent use.
+type queryLog struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func (l *queryLog) add(s string) {
+	l.mu.Lock()
+	l.queries = append(l.queries, s)
+	l.mu.Unlock()
+}
+
+// GeneratedQueries returns the queries generated by the query builder, in the
+// order they were generated, if the RecordQueries option is set. It returns nil
+// if the queries are not recorded.
+func (q *QueryBuilder) GeneratedQueries() []string {
+	if q.queryLog == nil {
+		return nil
+	}
+	q.queryLog.mu.Lock()
+	defer q.queryLog.mu.Unlock()
+	return append([]string{}, q.queryLog.queries...)
+}
@@ -0,0 +1,56 @@
+package qb
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestQueryBuilder_GeneratedQueries(t *testing.T) {
+	q := Must(testTable{}, RecordQueries(true))
+	if got := q.GeneratedQueries(); len(got) != 0 {
+		t.Errorf("QueryBuilder.GeneratedQueries() = %v, want []", got)
+	}
+	q.Select()
+	q.DeleteIfNotDeleted()
+	q.InsertReturningAll()
+	q.SelectWith(QUESTION)
+	// Fragments of queries are not recorded.
+	q.ByIDClause(1)
+	q.ScanColumns("")
+	q.NowExpr()
+	want := []string{
+		"SELECT id, name, email FROM users WHERE id = $1",
+		"UPDATE users SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL",
+		"INSERT INTO users (id, name, email) VALUES ($1, $2, $3) RETURNING id, name, email",
+		"SELECT id, name, email FROM users WHERE id = ?",
+	}
+	if got := q.GeneratedQueries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("QueryBuilder.GeneratedQueries() = %v, want %v", got, want)
+	}
+
+	q = Must(testTable{})
+	q.Select()
+	if got := q.GeneratedQueries(); got != nil {
+		t.Errorf("QueryBuilder.GeneratedQueries() = %v, want nil", got)
+	}
+}
+
+func TestQueryBuilder_GeneratedQueries_concurrent(t *testing.T) {
+	q := Must(testTable{}, RecordQueries(true))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				q.Select()
+				q.GeneratedQueries()
+			}
+		}()
+	}
+	wg.Wait()
+	if got := len(q.GeneratedQueries()); got != 100 {
+		t.Errorf("len(QueryBuilder.GeneratedQueries()) = %d, want 100", got)
+	}
+}
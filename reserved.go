@@ -0,0 +1,100 @@
+package qb
+
+import "strings"
+
+// PostgresReservedWords are the reserved key words of PostgreSQL, that cannot
+// be used as table or column names without quotes. They are the default words
+// of the QuoteReserved option in all the dialects except MySQL.
+var PostgresReservedWords = []string{
+	"ALL", "ANALYSE", "ANALYZE", "AND", "ANY", "ARRAY", "AS", "ASC",
+	"ASYMMETRIC", "AUTHORIZATION", "BINARY", "BOTH", "CASE", "CAST", "CHECK",
+	"COLLATE", "COLLATION", "COLUMN", "CONCURRENTLY", "CONSTRAINT", "CREATE",
+	"CROSS", "CURRENT_CATALOG", "CURRENT_DATE", "CURRENT_ROLE",
+	"CURRENT_SCHEMA", "CURRENT_TIME", "CURRENT_TIMESTAMP", "CURRENT_USER",
+	"DEFAULT", "DEFERRABLE", "DESC", "DISTINCT", "DO", "ELSE", "END", "EXCEPT",
+	"FALSE", "FETCH", "FOR", "FOREIGN", "FREEZE", "FROM", "FULL", "GRANT",
+	"GROUP", "HAVING", "ILIKE", "IN", "INITIALLY", "INNER", "INTERSECT",
+	"INTO", "IS", "ISNULL", "JOIN", "LATERAL", "LEADING", "LEFT", "LIKE",
+	"LIMIT", "LOCALTIME", "LOCALTIMESTAMP", "NATURAL", "NOT", "NOTNULL",
+	"NULL", "OFFSET", "ON", "ONLY", "OR", "ORDER", "OUTER", "OVERLAPS",
+	"PLACING", "PRIMARY", "REFERENCES", "RETURNING", "RIGHT", "SELECT",
+	"SESSION_USER", "SIMILAR", "SOME", "SYMMETRIC", "SYSTEM_USER", "TABLE",
+	"TABLESAMPLE", "THEN", "TO", "TRAILING", "TRUE", "UNION", "UNIQUE", "USER",
+	"USING", "VARIADIC", "VERBOSE", "WHEN", "WHERE", "WINDOW", "WITH",
+}
+
+// MySQLReservedWords are the most common reserved words of MySQL, that cannot
+// be used as table or column names without quotes. They are the default words
+// of the QuoteReserved option in the MySQL dialect.
+var MySQLReservedWords = []string{
+	"ACCESSIBLE", "ADD", "ALL", "ALTER", "ANALYZE", "AND", "AS", "ASC",
+	"BEFORE", "BETWEEN", "BIGINT", "BINARY", "BLOB", "BOTH", "BY", "CALL",
+	"CASCADE", "CASE", "CHANGE", "CHAR", "CHARACTER", "CHECK", "COLLATE",
+	"COLUMN", "CONDITION", "CONSTRAINT", "CONTINUE", "CONVERT", "CREATE",
+	"CROSS", "CUBE", "CURRENT_DATE", "CURRENT_TIME", "CURRENT_TIMESTAMP",
+	"CURRENT_USER", "CURSOR", "DATABASE", "DATABASES", "DEC", "DECIMAL",
+	"DECLARE", "DEFAULT", "DELAYED", "DELETE", "DESC", "DESCRIBE",
+	"DETERMINISTIC", "DISTINCT", "DISTINCTROW", "DIV", "DOUBLE", "DROP",
+	"DUAL", "EACH", "ELSE", "ELSEIF", "ENCLOSED", "ESCAPED", "EXCEPT",
+	"EXISTS", "EXIT", "EXPLAIN", "FALSE", "FETCH", "FLOAT", "FOR", "FORCE",
+	"FOREIGN", "FROM", "FULLTEXT", "FUNCTION", "GENERATED", "GET", "GRANT",
+	"GROUP", "GROUPS", "HAVING", "HIGH_PRIORITY", "IF", "IGNORE", "IN",
+	"INDEX", "INFILE", "INNER", "INOUT", "INSERT", "INT", "INTEGER",
+	"INTERSECT", "INTERVAL", "INTO", "IS", "ITERATE", "JOIN", "KEY", "KEYS",
+	"KILL", "LAG", "LEAD", "LEADING", "LEAVE", "LEFT", "LIKE", "LIMIT",
+	"LINEAR", "LINES", "LOAD", "LOCALTIME", "LOCALTIMESTAMP", "LOCK", "LONG",
+	"LOOP", "MATCH", "MAXVALUE", "MOD", "MODIFIES", "NATURAL", "NOT", "NULL",
+	"NUMERIC", "OF", "ON", "OPTIMIZE", "OPTION", "OPTIONALLY", "OR", "ORDER",
+	"OUT", "OUTER", "OVER", "PARTITION", "PRECISION", "PRIMARY", "PROCEDURE",
+	"PURGE", "RANGE", "RANK", "READ", "READS", "REAL", "RECURSIVE",
+	"REFERENCES", "REGEXP", "RELEASE", "RENAME", "REPEAT", "REPLACE",
+	"REQUIRE", "RESIGNAL", "RESTRICT", "RETURN", "REVOKE", "RIGHT", "RLIKE",
+	"ROW", "ROWS", "SCHEMA", "SCHEMAS", "SELECT", "SEPARATOR", "SET", "SHOW",
+	"SIGNAL", "SMALLINT", "SPATIAL", "SQL", "STARTING", "STORED",
+	"STRAIGHT_JOIN", "SYSTEM", "TABLE", "TERMINATED", "THEN", "TO",
+	"TRAILING", "TRIGGER", "TRUE", "UNDO", "UNION", "UNIQUE", "UNLOCK",
+	"UNSIGNED", "UPDATE", "USAGE", "USE", "USING", "VALUES", "VARCHAR",
+	"VARYING", "VIRTUAL", "WHEN", "WHERE", "WHILE", "WINDOW", "WITH", "WRITE",
+	"XOR", "ZEROFILL",
+}
+
+// defaultReservedWords returns the default reserved words of the dialect.
+func defaultReservedWords(d SQLDialect) []string {
+	if d == MYSQL {
+		return MySQLReservedWords
+	}
+	return PostgresReservedWords
+}
+
+// isReserved reports whether the given name is one of the ReservedWords,
+// ignoring the case.
+func (q *QueryBuilder) isReserved(name string) bool {
+	for _, w := range q.ReservedWords {
+		if strings.EqualFold(w, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// isIdentifier reports whether the given name is the table or one of the
+// columns of the query builder.
+func (q *QueryBuilder) isIdentifier(name string) bool {
+	switch name {
+	case q.Table, q.idColumn(), q.deletedColumn(), q.TenantColumn, q.VersionColumn:
+		return true
+	default:
+		return q.HasColumn(name)
+	}
+}
+
+// quoteReserved returns the query with the table and column names that are
+// reserved words quoted. The named parameters and the casts are not modified.
+func (q *QueryBuilder) quoteReserved(s string) string {
+	return mapWords(s, func(w string, prev byte) string {
+		if prev != ':' && prev != '@' && q.isIdentifier(w) && q.isReserved(w) {
+			return q.QuoteIdent(w)
+		}
+		return w
+	})
+}
@@ -0,0 +1,39 @@
+package qb
+
+import "testing"
+
+type testReserved struct {
+	ID    string `db:"id"`
+	User  string `db:"user"`
+	Order int    `db:"order"`
+	Name  string `db:"name"`
+	Group string `db:"group"`
+}
+
+func TestQueryBuilder_QuoteReserved(t *testing.T) {
+	pg := Must(testReserved{}, TableName("user"), QuoteReserved(nil), TenantColumn("group"))
+	mysql := Must(testReserved{}, TableName("user"), Dialect(MYSQL), BindType(QUESTION), QuoteReserved(nil))
+	custom := Must(testReserved{}, QuoteReserved([]string{"NAME"}), NamedType(AT), Lowercase(true))
+	upsert, _ := pg.Upsert()
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"Select", pg.Select(), `SELECT id, "user", "order", name, "group" FROM "user" WHERE id = $1 AND "group" = $2`},
+		{"Update", pg.Update(), `UPDATE "user" SET "user" = $1, "order" = $2, name = $3, "group" = $4 WHERE id = $5 AND "group" = $6`},
+		{"NamedInsert", pg.NamedInsert(), `INSERT INTO "user" (id, "user", "order", name, "group") VALUES (:id, :user, :order, :name, :group)`},
+		{"Upsert", upsert, `INSERT INTO "user" (id, "user", "order", name, "group") VALUES ($1, $2, $3, $4, $5) ON CONFLICT (id) DO UPDATE SET "user" = EXCLUDED."user", "order" = EXCLUDED."order", name = EXCLUDED.name WHERE "user"."group" = EXCLUDED."group"`},
+		{"Select mysql", mysql.Select(), "SELECT id, user, `order`, name, `group` FROM user WHERE id = ?"},
+		{"Insert mysql", mysql.Insert(), "INSERT INTO user (id, user, `order`, name, `group`) VALUES (?, ?, ?, ?, ?)"},
+		{"Select custom", custom.Select(), `select id, user, order, "name", group from test_reserved where id = $1`},
+		{"NamedUpdate custom", custom.NamedUpdate(), `update test_reserved set user = @user, order = @order, "name" = @name, group = @group where id = @id`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("QueryBuilder.%s() = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
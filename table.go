@@ -1,13 +1,16 @@
 package qb
 
 import (
-	"errors"
 	"fmt"
 	"reflect"
 	"strings"
 	"unicode"
 )
 
+// gormTag is the tag key used by GORM, its values are parsed with
+// parseGormTag.
+const gormTag = "gorm"
+
 type table struct {
 	Name       string
 	Columns    []string
@@ -18,25 +21,31 @@ func isPrimaryKey(s string) bool {
 	return strings.EqualFold(s, "primaryKey") || strings.EqualFold(s, "pkey")
 }
 
-func (t *table) addColumn(name string) error {
-	if parts := strings.SplitN(name, ",", 2); len(parts) == 2 && isPrimaryKey(parts[1]) {
-		if t.PrimaryKey != "" && t.PrimaryKey != parts[0] {
-			return errors.New("table cannot have more than one primary key")
+// parseColumn returns the column name and if it is a primary key from the
+// value of a column tag.
+func parseColumn(s string) (string, bool) {
+	if parts := strings.SplitN(s, ",", 2); len(parts) == 2 && isPrimaryKey(parts[1]) {
+		return strings.TrimSpace(parts[0]), true
+	}
+	return strings.TrimSpace(s), false
+}
+
+func (t *table) addColumn(s string) error {
+	name, pkey := parseColumn(s)
+	if pkey {
+		if t.PrimaryKey != "" && t.PrimaryKey != name {
+			return fmt.Errorf("%w, found %s and %s", ErrMultiplePrimaryKeys, t.PrimaryKey, name)
 		}
-		name = strings.TrimSpace(parts[0])
-		t.Columns = append(t.Columns, name)
 		t.PrimaryKey = name
-		return nil
 	}
-
-	t.Columns = append(t.Columns, strings.TrimSpace(name))
+	t.Columns = append(t.Columns, name)
 	return nil
 }
 
 func (t *table) addColumnsFromTable(rt table) error {
 	if rt.PrimaryKey != "" {
 		if t.PrimaryKey != "" && t.PrimaryKey != rt.PrimaryKey {
-			return errors.New("table cannot have more than one primary key")
+			return fmt.Errorf("%w, found %s and %s", ErrMultiplePrimaryKeys, t.PrimaryKey, rt.PrimaryKey)
 		}
 		t.PrimaryKey = rt.PrimaryKey
 	}
@@ -44,6 +53,31 @@ func (t *table) addColumnsFromTable(rt table) error {
 	return nil
 }
 
+// removeColumns removes the given columns from the table, it fails if a column
+// is not present.
+func (t *table) removeColumns(names []string) error {
+	for _, name := range names {
+		i := indexOf(t.Columns, name)
+		if i < 0 {
+			return fmt.Errorf("%w %q in table %s", ErrUnknownColumn, name, t.Name)
+		}
+		t.Columns = append(t.Columns[:i:i], t.Columns[i+1:]...)
+		if t.PrimaryKey == name {
+			t.PrimaryKey = ""
+		}
+	}
+	return nil
+}
+
+func indexOf(s []string, name string) int {
+	for i, v := range s {
+		if v == name {
+			return i
+		}
+	}
+	return -1
+}
+
 func getTagValue(key string, f reflect.StructField) string {
 	s := f.Tag.Get(key)
 	if s == "-" {
@@ -52,11 +86,89 @@ func getTagValue(key string, f reflect.StructField) string {
 	return s
 }
 
-func getTableName(name string) string {
+// getTagValues returns the value of the first tag key present in the field. A
+// field tagged with "-" in a key is skipped even if other keys are present.
+// The values of the gorm key are converted with parseGormTag.
+func getTagValues(keys []string, f reflect.StructField) string {
+	for _, key := range keys {
+		s := f.Tag.Get(key)
+		if key == gormTag {
+			s = parseGormTag(s, f)
+		}
+		switch s {
+		case "":
+			continue
+		case "-":
+			return ""
+		default:
+			return s
+		}
+	}
+	return ""
+}
+
+// columnName returns the value of the first column tag present in the field,
+// see getTagValues. If infer is true, the exported fields without column tags,
+// other than embedded structs, use the field name in snake case.
+func columnName(tags []string, infer bool, f reflect.StructField) string {
+	if s := getTagValues(tags, f); s != "" || !infer {
+		return s
+	}
+	if !f.IsExported() || f.Anonymous {
+		return ""
+	}
+	for _, key := range tags {
+		if _, ok := f.Tag.Lookup(key); ok {
+			return ""
+		}
+	}
+	return snakeCase(f.Name)
+}
+
+// parseGormTag converts the value of a GORM tag, like "column:email;primaryKey",
+// to the format used in the db tag, "email,primaryKey". If the column option is
+// not present, the name is the field name in snake case, like in GORM. Fields
+// tagged with "-" or "-:all" are skipped.
+func parseGormTag(s string, f reflect.StructField) string {
+	if s == "" {
+		return ""
+	}
+	var name string
+	var pkey bool
+	for _, opt := range strings.Split(s, ";") {
+		key, value, _ := strings.Cut(opt, ":")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch {
+		case key == "-" && (value == "" || strings.EqualFold(value, "all")):
+			return "-"
+		case strings.EqualFold(key, "column"):
+			name = value
+		case strings.EqualFold(key, "primaryKey"), strings.EqualFold(key, "primary_key"):
+			pkey = true
+		}
+	}
+	if name == "" {
+		name = snakeCase(f.Name)
+	}
+	if pkey {
+		return name + ",primaryKey"
+	}
+	return name
+}
+
+// snakeCase converts a Go identifier to snake case, keeping acronyms
+// together, for example, UserID is converted to user_id and HTTPServer to
+// http_server. It is used for the inferred table and column names.
+func snakeCase(name string) string {
 	var b strings.Builder
-	for i, r := range name {
-		if unicode.IsUpper(r) && i != 0 {
-			b.WriteByte('_')
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
 		}
 		b.WriteRune(unicode.ToLower(r))
 	}
@@ -75,7 +187,7 @@ func structOf(i any) (reflect.Value, error) {
 		}
 	}
 
-	return reflect.Value{}, fmt.Errorf("%T is neither struct nor does it point to one", i)
+	return reflect.Value{}, fmt.Errorf("%T is %w", i, ErrNotStruct)
 }
 
 func fieldColumns(f reflect.StructField, o *options) (table, error) {
@@ -106,7 +218,7 @@ func fieldColumns(f reflect.StructField, o *options) (table, error) {
 		}
 
 		// Get the columns
-		if name := getTagValue(o.columnTag, field); name != "" {
+		if name := columnName(o.columnTags, o.inferCols, field); name != "" {
 			if err := t.addColumn(name); err != nil {
 				return table{}, err
 			}
@@ -115,14 +227,20 @@ func fieldColumns(f reflect.StructField, o *options) (table, error) {
 	return t, nil
 }
 
-func getTable(i any, o *options) (table, error) {
-	v, err := structOf(i)
-	if err != nil {
-		return table{}, err
+// structTypeOf returns the struct type of t, dereferencing pointer types.
+func structTypeOf(t reflect.Type) (reflect.Type, error) {
+	typ := t
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%v is %w", t, ErrNotStruct)
 	}
+	return typ, nil
+}
 
+func getTable(typ reflect.Type, o *options) (table, error) {
 	t := table{Name: o.tableName}
-	typ := v.Type()
 	for i, n := 0, typ.NumField(); i < n; i++ {
 		field := typ.Field(i)
 
@@ -143,7 +261,7 @@ func getTable(i any, o *options) (table, error) {
 		}
 
 		// Get the columns
-		if name := getTagValue(o.columnTag, field); name != "" {
+		if name := columnName(o.columnTags, o.inferCols, field); name != "" {
 			if err := t.addColumn(name); err != nil {
 				return table{}, err
 			}
@@ -151,7 +269,11 @@ func getTable(i any, o *options) (table, error) {
 	}
 
 	if t.Name == "" {
-		t.Name = getTableName(typ.Name())
+		t.Name = snakeCase(typ.Name())
+	}
+	if o.tablePrefix != "" {
+		i := strings.LastIndexByte(t.Name, '.')
+		t.Name = t.Name[:i+1] + o.tablePrefix + t.Name[i+1:]
 	}
 
 	return t, nil